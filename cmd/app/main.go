@@ -4,47 +4,163 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
+	// Quiet hours are evaluated in each user's timezone, so don't depend on
+	// the host having a zoneinfo database.
+	_ "time/tzdata"
 
+	"ministry-scheduler/internal/domain"
 	"ministry-scheduler/internal/handler"
 	"ministry-scheduler/internal/infra"
 	"ministry-scheduler/internal/usecase"
 )
 
 const (
-	readHeaderTimeout = 10 * time.Second
-	shutdownTimeout   = 30 * time.Second
+	readHeaderTimeout        = 10 * time.Second
+	shutdownTimeout          = 30 * time.Second
+	notificationWorkers      = 2
+	notificationPollInterval = 5 * time.Second
+	busyTimeRefreshInterval  = time.Hour
 )
 
 func main() {
-	dbPath := getEnvOrDefault("DB_PATH", "users.db")
+	storage := storageConfig{
+		Driver:      getEnvOrDefault("DB_DRIVER", driverSQLite),
+		DBPath:      getEnvOrDefault("DB_PATH", "users.db"),
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+	}
 	port := getEnvOrDefault("PORT", "8080")
+	lineChannelSecret := os.Getenv("LINE_CHANNEL_SECRET")
+	lineAccessToken := os.Getenv("LINE_CHANNEL_ACCESS_TOKEN")
+	lineAPIBaseURL := getEnvOrDefault("LINE_API_BASE_URL", infra.DefaultLineAPIBaseURL)
+	lineBotID := os.Getenv("LINE_BOT_ID")
+	defaultTimezone := os.Getenv("DEFAULT_TIMEZONE")
+	smtpConfig := infra.SMTPConfig{
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     getEnvOrDefault("SMTP_PORT", infra.DefaultSMTPPort),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+	}
+
+	if os.Getenv("MIGRATE_DRY_RUN") == "true" {
+		if dryRunErr := printPendingMigrations(storage); dryRunErr != nil {
+			log.Fatalf("Failed to check migrations: %v", dryRunErr)
+		}
+		return
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	// Users who haven't set a timezone get this one.
+	defaultZone := time.Local
+	if defaultTimezone != "" {
+		var zoneErr error
+		if defaultZone, zoneErr = time.LoadLocation(defaultTimezone); zoneErr != nil {
+			log.Fatalf("Invalid DEFAULT_TIMEZONE: %v", zoneErr)
+		}
+	}
 
 	log.Println("Starting Ministry Scheduler API...")
-	log.Printf("Database path: %s", dbPath)
+	log.Printf("Database driver: %s", storage.Driver)
+	switch storage.Driver {
+	case driverSQLite:
+		log.Printf("Database path: %s", storage.DBPath)
+	case driverMemory:
+		log.Println("Data is kept in memory and lost when the server stops")
+	}
 	log.Printf("Port: %s", port)
 
-	db, err := infra.InitializeDB(dbPath)
+	var emailNotifier *infra.EmailNotifier
+	if smtpConfig.Host != "" {
+		var emailErr error
+		if emailNotifier, emailErr = infra.NewEmailNotifier(smtpConfig); emailErr != nil {
+			log.Fatalf("Failed to configure email notifications: %v", emailErr)
+		}
+		log.Printf("Email notifications enabled via %s", smtpConfig.Host)
+	}
+
+	repos, err := openRepositories(storage)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer func() {
-		if closeErr := db.Close(); closeErr != nil {
+		if closeErr := repos.Close(); closeErr != nil {
 			log.Printf("Error closing database: %v", closeErr)
 		}
 	}()
 
-	userRepo := infra.NewSQLUserRepository(db)
+	userRepo := repos.users
 	userUsecase := usecase.NewUserUsecase(userRepo)
 	userHandler := handler.NewUserHandler(userUsecase)
 
 	mux := http.NewServeMux()
 	userHandler.RegisterRoutes(mux)
 
+	scheduleQueryUsecase := usecase.NewScheduleQueryUsecase(userRepo, defaultZone, time.Now)
+	scheduleQueryHandler := handler.NewScheduleQueryHandler(scheduleQueryUsecase)
+	scheduleQueryHandler.RegisterRoutes(mux)
+
+	var lineClient domain.LineClient
+	notifiers := []domain.Notifier{infra.NewInAppNotifier(repos.inAppNotifications)}
+	if lineAccessToken != "" {
+		lineClient = infra.NewLineHTTPClient(lineAPIBaseURL, lineAccessToken)
+		notifiers = append(notifiers, infra.NewLineNotifier(lineClient))
+	}
+	if emailNotifier != nil {
+		notifiers = append(notifiers, emailNotifier)
+	}
+
+	notificationUsecase := usecase.NewNotificationUsecase(
+		repos.notifications, repos.inAppNotifications, userRepo, logger, notifiers...)
+	notificationHandler := handler.NewNotificationHandler(notificationUsecase)
+	notificationHandler.RegisterRoutes(mux)
+
+	lineBindingUsecase := usecase.NewLineBindingUsecase(
+		userRepo, repos.lineBindingCodes, repos.transactor, notificationUsecase)
+	lineBindingHandler := handler.NewLineBindingHandler(lineBindingUsecase, lineBotID)
+	lineBindingHandler.RegisterRoutes(mux)
+
+	busyTimeUsecase := usecase.NewBusyTimeUsecase(
+		repos.busyTimes, userRepo, repos.transactor, infra.NewHTTPCalendarFetcher(), logger)
+	busyTimeHandler := handler.NewBusyTimeHandler(busyTimeUsecase)
+	busyTimeHandler.RegisterRoutes(mux)
+
+	if lineChannelSecret != "" && lineClient != nil {
+		lineBotUsecase := usecase.NewLineBotUsecase(lineBindingUsecase, scheduleQueryUsecase)
+		lineWebhookHandler := handler.NewLineWebhookHandler(lineChannelSecret, lineBotUsecase, lineClient, logger)
+		lineWebhookHandler.RegisterRoutes(mux)
+		log.Println("Line webhook enabled at /line/webhook")
+	} else {
+		log.Println("Line webhook disabled: LINE_CHANNEL_SECRET or LINE_CHANNEL_ACCESS_TOKEN not set")
+	}
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	for range notificationWorkers {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			notificationUsecase.Run(workerCtx, notificationPollInterval)
+		}()
+	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		busyTimeUsecase.Run(workerCtx, busyTimeRefreshInterval)
+	}()
+	// Runs before the database is closed.
+	defer func() {
+		stopWorkers()
+		workers.Wait()
+	}()
+
 	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		fmt.Fprintln(w, `{"message": "Ministry Scheduler API", "version": "1.0.0"}`)
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/infra"
+	"ministry-scheduler/internal/infra/memory"
+	"ministry-scheduler/internal/infra/postgres"
+)
+
+const (
+	driverSQLite   = "sqlite"
+	driverPostgres = "postgres"
+	driverMemory   = "memory"
+)
+
+var errDatabaseURLRequired = errors.New("DATABASE_URL is required for the postgres driver")
+
+type storageConfig struct {
+	Driver      string
+	DBPath      string
+	DatabaseURL string
+}
+
+// repositories is the storage the usecases are built on, for whichever
+// driver was selected. db is nil for the memory driver.
+type repositories struct {
+	db                 *sql.DB
+	users              domain.UserRepository
+	lineBindingCodes   domain.LineBindingCodeRepository
+	notifications      domain.NotificationRepository
+	inAppNotifications domain.InAppNotificationRepository
+	busyTimes          domain.BusyTimeRepository
+	transactor         domain.Transactor
+}
+
+// openRepositories connects to the configured database and applies pending
+// migrations.
+func openRepositories(config storageConfig) (*repositories, error) {
+	switch config.Driver {
+	case driverSQLite:
+		db, err := infra.InitializeDB(config.DBPath)
+		if err != nil {
+			return nil, err
+		}
+		return &repositories{
+			db:                 db,
+			users:              infra.NewSQLUserRepository(db),
+			lineBindingCodes:   infra.NewSQLLineBindingCodeRepository(db),
+			notifications:      infra.NewSQLNotificationRepository(db),
+			inAppNotifications: infra.NewSQLInAppNotificationRepository(db),
+			busyTimes:          infra.NewSQLBusyTimeRepository(db),
+			transactor:         infra.NewSQLTransactor(db),
+		}, nil
+
+	case driverPostgres:
+		if config.DatabaseURL == "" {
+			return nil, errDatabaseURLRequired
+		}
+		db, err := postgres.InitializeDB(config.DatabaseURL)
+		if err != nil {
+			return nil, err
+		}
+		return &repositories{
+			db:                 db,
+			users:              postgres.NewUserRepository(db),
+			lineBindingCodes:   postgres.NewLineBindingCodeRepository(db),
+			notifications:      postgres.NewNotificationRepository(db),
+			inAppNotifications: postgres.NewInAppNotificationRepository(db),
+			busyTimes:          postgres.NewBusyTimeRepository(db),
+			transactor:         postgres.NewTransactor(db),
+		}, nil
+
+	case driverMemory:
+		store := memory.NewStore()
+		return &repositories{
+			users:              memory.NewUserRepository(store),
+			lineBindingCodes:   memory.NewLineBindingCodeRepository(store),
+			notifications:      memory.NewNotificationRepository(store),
+			inAppNotifications: memory.NewInAppNotificationRepository(store),
+			busyTimes:          memory.NewBusyTimeRepository(store),
+			transactor:         memory.NewTransactor(store),
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q", config.Driver)
+	}
+}
+
+func (r *repositories) Close() error {
+	if r.db == nil {
+		return nil
+	}
+	return r.db.Close()
+}
+
+// printPendingMigrations lists the migrations startup would apply without
+// applying them.
+func printPendingMigrations(config storageConfig) error {
+	var (
+		pending []infra.Migration
+		err     error
+	)
+	switch config.Driver {
+	case driverSQLite:
+		pending, err = pendingMigrations(infra.OpenDB, infra.PendingMigrations, config.DBPath)
+	case driverPostgres:
+		if config.DatabaseURL == "" {
+			return errDatabaseURLRequired
+		}
+		pending, err = pendingMigrations(postgres.OpenDB, postgres.PendingMigrations, config.DatabaseURL)
+	case driverMemory:
+		log.Println("The memory driver has no schema to migrate")
+		return nil
+	default:
+		return fmt.Errorf("unknown DB_DRIVER %q", config.Driver)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(pending) == 0 {
+		log.Println("Database schema is up to date")
+	}
+	for _, migration := range pending {
+		log.Printf("Pending migration %04d_%s", migration.Version, migration.Name)
+	}
+	return nil
+}
+
+func pendingMigrations(
+	open func(string) (*sql.DB, error),
+	pending func(context.Context, *sql.DB) ([]infra.Migration, error),
+	dsn string,
+) ([]infra.Migration, error) {
+	db, err := open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return pending(context.Background(), db)
+}
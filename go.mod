@@ -2,4 +2,10 @@ module ministry-scheduler
 
 go 1.24.5
 
-require github.com/mattn/go-sqlite3 v1.14.30
+require (
+	github.com/fergusstrange/embedded-postgres v1.34.0
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.30
+)
+
+require github.com/xi2/xz v0.0.0-20171230120015-48954b6210f8 // indirect
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type BusySourceKind string
+
+const (
+	BusySourceUpload BusySourceKind = "upload"
+	BusySourceURL    BusySourceKind = "url"
+)
+
+// BusySource is a calendar a user imported busy times from: an uploaded ICS
+// file or a private ICS URL that is fetched again periodically.
+type BusySource struct {
+	ID     int64          `json:"id"`
+	UserID int64          `json:"user_id"`
+	Kind   BusySourceKind `json:"kind"`
+	// URL often embeds a secret token, so it is never returned by the API.
+	URL          string     `json:"-"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// BusyBlock is a period during which a user is busy. Only the times are kept;
+// event titles and details stay in the user's own calendar.
+type BusyBlock struct {
+	SourceID int64     `json:"source_id"`
+	UserID   int64     `json:"user_id"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+}
+
+var (
+	ErrBusySourceNotFound  = errors.New("busy time source not found")
+	ErrInvalidCalendar     = errors.New("invalid calendar")
+	ErrInvalidCalendarURL  = errors.New("calendar URL must be http, https or webcal")
+	ErrCalendarUnavailable = errors.New("calendar could not be fetched")
+)
+
+const (
+	// BusyTimeLookback and BusyTimeHorizon bound which events are imported,
+	// relative to the time of the import.
+	BusyTimeLookback = 24 * time.Hour
+	BusyTimeHorizon  = 180 * 24 * time.Hour
+
+	// MaxCalendarSize limits uploaded and fetched ICS files.
+	MaxCalendarSize = 5 << 20
+	// MaxBusyBlocksPerCalendar limits the busy periods imported from one
+	// calendar, which bounds the work of expanding recurring events.
+	MaxBusyBlocksPerCalendar = 5000
+)
+
+type BusyTimeRepository interface {
+	CreateSource(ctx context.Context, source *BusySource) (*BusySource, error)
+	GetSource(ctx context.Context, id int64) (*BusySource, error)
+	ListSources(ctx context.Context, userID int64) ([]*BusySource, error)
+	ListURLSources(ctx context.Context) ([]*BusySource, error)
+	UpdateSourceSync(ctx context.Context, source *BusySource) error
+	DeleteSource(ctx context.Context, id int64) error
+	// ReplaceBlocks swaps all blocks of a source for blocks.
+	ReplaceBlocks(ctx context.Context, sourceID int64, blocks []BusyBlock) error
+	// ListBlocks returns the user's blocks that overlap [from, to), by start.
+	ListBlocks(ctx context.Context, userID int64, from, to time.Time) ([]BusyBlock, error)
+}
+
+// CalendarFetcher downloads an ICS calendar from a subscription URL.
+type CalendarFetcher interface {
+	Fetch(ctx context.Context, url string) ([]byte, error)
+}
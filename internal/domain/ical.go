@@ -0,0 +1,504 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	icsDateLayout     = "20060102"
+	icsDateTimeLayout = "20060102T150405"
+	icsUTCLayout      = "20060102T150405Z"
+
+	// maxICSIterations caps how many recurrence periods of one event are
+	// expanded.
+	maxICSIterations = 10000
+	hoursPerDay      = 24
+)
+
+var icsDurationRegex = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+type icsEvent struct {
+	uid          string
+	start        time.Time
+	end          time.Time
+	allDay       bool
+	duration     string
+	rrule        string
+	exdates      []time.Time
+	recurrenceID *time.Time
+	skip         bool
+}
+
+// ParseBusyBlocks returns the busy periods of the events in an iCalendar file
+// that overlap [from, to). Transparent ("free") and cancelled events are
+// skipped. Daily, weekly, monthly and yearly recurrences are expanded, with
+// BYDAY supported for weekly rules and BYMONTHDAY for monthly ones. Times
+// without a timezone are read in from's location. A calendar with more than
+// MaxBusyBlocksPerCalendar busy periods in the range is rejected.
+func ParseBusyBlocks(data []byte, from, to time.Time) ([]BusyBlock, error) {
+	events, err := parseICSEvents(data, from.Location())
+	if err != nil {
+		return nil, err
+	}
+
+	// Instances moved by a RECURRENCE-ID override replace the master's.
+	overridden := make(map[string][]time.Time)
+	for _, event := range events {
+		if event.recurrenceID != nil {
+			overridden[event.uid] = append(overridden[event.uid], *event.recurrenceID)
+		}
+	}
+
+	var blocks []BusyBlock
+	for _, event := range events {
+		if event.skip {
+			continue
+		}
+		length := event.length()
+		if length <= 0 {
+			continue
+		}
+
+		var excluded []time.Time
+		if event.recurrenceID == nil {
+			excluded = append(slices.Clone(event.exdates), overridden[event.uid]...)
+		}
+		// The earliest start that can still overlap from, with a day's slack
+		// for all-day events across DST changes.
+		after := from.Add(-length - hoursPerDay*time.Hour)
+		for _, start := range event.occurrences(after, to) {
+			end := start.Add(length)
+			if event.allDay {
+				end = start.AddDate(0, 0, event.days())
+			}
+			if !end.After(from) || !start.Before(to) || containsTime(excluded, start) {
+				continue
+			}
+			if len(blocks) == MaxBusyBlocksPerCalendar {
+				return nil, fmt.Errorf("%w: more than %d busy times", ErrInvalidCalendar, MaxBusyBlocksPerCalendar)
+			}
+			blocks = append(blocks, BusyBlock{Start: start, End: end})
+		}
+	}
+
+	slices.SortFunc(blocks, func(a, b BusyBlock) int { return a.Start.Compare(b.Start) })
+	return blocks, nil
+}
+
+func parseICSEvents(data []byte, loc *time.Location) ([]*icsEvent, error) {
+	lines := unfoldICSLines(data)
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("%w: missing BEGIN:VCALENDAR", ErrInvalidCalendar)
+	}
+
+	var events []*icsEvent
+	var current *icsEvent
+	// depth counts the components open inside the current event, such as a
+	// VALARM, whose DURATION or DESCRIPTION must not apply to the event.
+	depth := 0
+	for _, line := range lines {
+		property, ok := parseICSProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case property.name == "BEGIN" && strings.EqualFold(property.value, "VEVENT"):
+			current, depth = &icsEvent{}, 0
+		case property.name == "END" && strings.EqualFold(property.value, "VEVENT"):
+			if current == nil || current.start.IsZero() {
+				return nil, fmt.Errorf("%w: event without DTSTART", ErrInvalidCalendar)
+			}
+			events = append(events, current)
+			current = nil
+		case current == nil:
+		case property.name == "BEGIN":
+			depth++
+		case property.name == "END":
+			depth = max(depth-1, 0)
+		case depth == 0:
+			if err := current.apply(property, loc); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return events, nil
+}
+
+func (e *icsEvent) apply(property icsProperty, loc *time.Location) error {
+	switch property.name {
+	case "UID":
+		e.uid = property.value
+	case "DTSTART":
+		start, allDay, err := parseICSTime(property, loc)
+		if err != nil {
+			return err
+		}
+		e.start, e.allDay = start, allDay
+	case "DTEND":
+		end, _, err := parseICSTime(property, loc)
+		if err != nil {
+			return err
+		}
+		e.end = end
+	case "DURATION":
+		e.duration = property.value
+	case "RRULE":
+		e.rrule = property.value
+	case "EXDATE":
+		for value := range strings.SplitSeq(property.value, ",") {
+			exdate, _, err := parseICSTime(icsProperty{params: property.params, value: value}, loc)
+			if err != nil {
+				return err
+			}
+			e.exdates = append(e.exdates, exdate)
+		}
+	case "RECURRENCE-ID":
+		recurrenceID, _, err := parseICSTime(property, loc)
+		if err != nil {
+			return err
+		}
+		e.recurrenceID = &recurrenceID
+	case "TRANSP":
+		e.skip = e.skip || strings.EqualFold(property.value, "TRANSPARENT")
+	case "STATUS":
+		e.skip = e.skip || strings.EqualFold(property.value, "CANCELLED")
+	}
+	return nil
+}
+
+// length is the event's duration. All-day events end after days() calendar
+// days instead, so DST changes don't shift the end.
+func (e *icsEvent) length() time.Duration {
+	switch {
+	case !e.end.IsZero():
+		return e.end.Sub(e.start)
+	case e.duration != "":
+		duration, ok := parseICSDuration(e.duration)
+		if !ok {
+			return 0
+		}
+		return duration
+	case e.allDay:
+		return hoursPerDay * time.Hour
+	default:
+		return 0
+	}
+}
+
+func (e *icsEvent) days() int {
+	return max(1, int((e.length()+time.Hour)/(hoursPerDay*time.Hour)))
+}
+
+// icsRule is a parsed RRULE.
+type icsRule struct {
+	freq      string
+	interval  int
+	count     int
+	until     time.Time
+	weekdays  []int
+	monthDays []int
+}
+
+func (e *icsEvent) rule(before time.Time) icsRule {
+	values := make(map[string]string)
+	for part := range strings.SplitSeq(e.rrule, ";") {
+		if key, value, ok := strings.Cut(part, "="); ok {
+			values[strings.ToUpper(key)] = value
+		}
+	}
+
+	rule := icsRule{freq: strings.ToUpper(values["FREQ"]), until: before}
+	rule.interval, _ = strconv.Atoi(values["INTERVAL"])
+	rule.interval = max(rule.interval, 1)
+	rule.count, _ = strconv.Atoi(values["COUNT"])
+	if value, ok := values["UNTIL"]; ok {
+		if parsed, allDay, err := parseICSTime(icsProperty{value: value}, e.start.Location()); err == nil {
+			rule.until = parsed
+			if allDay {
+				// A DATE includes the whole day (RFC 5545 3.3.10).
+				rule.until = parsed.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			}
+		}
+	}
+	for day := range strings.SplitSeq(values["BYDAY"], ",") {
+		if index := icsWeekdayIndex(day); index >= 0 {
+			rule.weekdays = append(rule.weekdays, index)
+		}
+	}
+	slices.Sort(rule.weekdays)
+	for day := range strings.SplitSeq(values["BYMONTHDAY"], ",") {
+		if n, err := strconv.Atoi(day); err == nil && n != 0 && n >= -31 && n <= 31 {
+			rule.monthDays = append(rule.monthDays, n)
+		}
+	}
+	return rule
+}
+
+// occurrences lists the event's start times in [after, before), plus any
+// earlier ones in the same recurrence period.
+func (e *icsEvent) occurrences(after, before time.Time) []time.Time {
+	if e.rrule == "" || e.recurrenceID != nil {
+		return []time.Time{e.start}
+	}
+
+	rule := e.rule(before)
+	if rule.until.Before(after) {
+		return nil
+	}
+
+	// Expansion starts near after rather than at DTSTART, so old recurring
+	// events cost no more than new ones. COUNT still counts from DTSTART.
+	first := e.firstStep(rule, after)
+	seen := e.occurrencesBefore(rule, first)
+	var starts []time.Time
+	for i := range maxICSIterations {
+		for _, start := range e.periodCandidates(rule, (first+i)*rule.interval) {
+			if !start.Before(before) || start.After(rule.until) || (rule.count > 0 && seen >= rule.count) {
+				return starts
+			}
+			seen++
+			starts = append(starts, start)
+		}
+	}
+	return starts
+}
+
+// firstStep returns a period index at or before the one containing after.
+func (e *icsEvent) firstStep(rule icsRule, after time.Time) int {
+	if !after.After(e.start) {
+		return 0
+	}
+
+	// One period of slack covers DST shifts and partial periods.
+	var periods int
+	switch rule.freq {
+	case "DAILY":
+		periods = int(after.Sub(e.start) / (hoursPerDay * time.Hour))
+	case "WEEKLY":
+		periods = int(after.Sub(e.start) / (daysPerWeek * hoursPerDay * time.Hour))
+	case "MONTHLY":
+		periods = (after.Year()-e.start.Year())*monthsPerYear + int(after.Month()-e.start.Month())
+	case "YEARLY":
+		periods = after.Year() - e.start.Year()
+	default:
+		return 0
+	}
+	return max(0, periods/rule.interval-1)
+}
+
+// occurrencesBefore counts the occurrences in the periods before step first,
+// for COUNT. Rules that would take more than maxICSIterations periods to
+// count are treated as exhausted.
+func (e *icsEvent) occurrencesBefore(rule icsRule, first int) int {
+	if rule.count == 0 || first == 0 {
+		return 0
+	}
+
+	switch {
+	case rule.freq == "DAILY", rule.freq == "WEEKLY" && len(rule.weekdays) == 0:
+		return first
+	case rule.freq == "WEEKLY":
+		return len(e.periodCandidates(rule, 0)) + (first-1)*len(rule.weekdays)
+	case first > maxICSIterations:
+		return rule.count
+	}
+
+	seen := 0
+	for step := 0; step < first && seen < rule.count; step++ {
+		seen += len(e.periodCandidates(rule, step*rule.interval))
+	}
+	return seen
+}
+
+// periodCandidates returns the starts, in order, in the period step periods
+// after the first. Dates that don't exist, such as the 31st of a 30-day
+// month, are skipped as RFC 5545 requires.
+func (e *icsEvent) periodCandidates(rule icsRule, step int) []time.Time {
+	switch rule.freq {
+	case "DAILY":
+		return []time.Time{e.start.AddDate(0, 0, step)}
+	case "WEEKLY":
+		return e.weeklyCandidates(step, rule.weekdays)
+	case "MONTHLY":
+		return e.monthlyCandidates(step, rule.monthDays)
+	case "YEARLY":
+		if start, ok := e.onDay(e.start.Year()+step, e.start.Month(), e.start.Day()); ok {
+			return []time.Time{start}
+		}
+		return nil
+	default:
+		if step == 0 {
+			return []time.Time{e.start}
+		}
+		return nil
+	}
+}
+
+// weeklyCandidates returns the starts in the week step weeks after the first.
+func (e *icsEvent) weeklyCandidates(step int, weekdays []int) []time.Time {
+	if len(weekdays) == 0 {
+		return []time.Time{e.start.AddDate(0, 0, daysPerWeek*step)}
+	}
+
+	var starts []time.Time
+	monday := startOfWeek(e.start).AddDate(0, 0, daysPerWeek*step)
+	for _, weekday := range weekdays {
+		if start := monday.AddDate(0, 0, weekday); !start.Before(e.start) {
+			starts = append(starts, start)
+		}
+	}
+	return starts
+}
+
+// monthlyCandidates returns the starts in the month step months after the
+// first, on DTSTART's day or the BYMONTHDAY days (negative ones count from
+// the end of the month).
+func (e *icsEvent) monthlyCandidates(step int, monthDays []int) []time.Time {
+	firstOfMonth := time.Date(e.start.Year(), e.start.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC)
+	if len(monthDays) == 0 {
+		monthDays = []int{e.start.Day()}
+	}
+
+	var starts []time.Time
+	for _, day := range monthDays {
+		if day < 0 {
+			day += firstOfMonth.AddDate(0, 1, -1).Day() + 1
+		}
+		start, ok := e.onDay(firstOfMonth.Year(), firstOfMonth.Month(), day)
+		if ok && !start.Before(e.start) {
+			starts = append(starts, start)
+		}
+	}
+	slices.SortFunc(starts, time.Time.Compare)
+	return slices.CompactFunc(starts, time.Time.Equal)
+}
+
+// onDay returns DTSTART's time of day on the given date, and false if the
+// date doesn't exist.
+func (e *icsEvent) onDay(year int, month time.Month, day int) (time.Time, bool) {
+	start := time.Date(year, month, day, e.start.Hour(), e.start.Minute(), e.start.Second(), 0, e.start.Location())
+	return start, day >= 1 && start.Day() == day && start.Month() == month
+}
+
+// icsWeekdayIndex counts days from Monday; prefixes like "1MO" are ignored.
+func icsWeekdayIndex(day string) int {
+	day = strings.ToUpper(strings.TrimLeft(day, "+-0123456789"))
+	return slices.Index([]string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}, day)
+}
+
+func parseICSTime(property icsProperty, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(property.value)
+	if tzid := property.params["TZID"]; tzid != "" {
+		// Unknown names, such as Windows zone names, fall back to loc.
+		if zone, err := time.LoadLocation(strings.Trim(tzid, `"`)); err == nil {
+			loc = zone
+		}
+	}
+
+	var parsed time.Time
+	var err error
+	allDay := false
+	switch {
+	case strings.EqualFold(property.params["VALUE"], "DATE") || len(value) == len(icsDateLayout):
+		parsed, err = time.ParseInLocation(icsDateLayout, value, loc)
+		allDay = true
+	case strings.HasSuffix(value, "Z"):
+		parsed, err = time.Parse(icsUTCLayout, value)
+	default:
+		parsed, err = time.ParseInLocation(icsDateTimeLayout, value, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: bad time %q", ErrInvalidCalendar, value)
+	}
+	return parsed, allDay, nil
+}
+
+func parseICSDuration(value string) (time.Duration, bool) {
+	match := icsDurationRegex.FindStringSubmatch(strings.ToUpper(value))
+	if match == nil {
+		return 0, false
+	}
+
+	day := hoursPerDay * time.Hour
+	units := []time.Duration{daysPerWeek * day, day, time.Hour, time.Minute, time.Second}
+	var duration time.Duration
+	for i, unit := range units {
+		n, _ := strconv.Atoi(match[i+2])
+		duration += time.Duration(n) * unit
+	}
+	if match[1] == "-" {
+		duration = -duration
+	}
+	return duration, true
+}
+
+func parseICSProperty(line string) (icsProperty, bool) {
+	// The value starts at the first colon outside a quoted parameter.
+	inQuotes := false
+	split := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			split = i
+			break
+		}
+	}
+	if split < 0 {
+		return icsProperty{}, false
+	}
+
+	parts := strings.Split(line[:split], ";")
+	property := icsProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string, len(parts)-1),
+		value:  line[split+1:],
+	}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			property.params[strings.ToUpper(key)] = value
+		}
+	}
+	return property, true
+}
+
+// unfoldICSLines joins continuation lines, which start with a space or tab.
+func unfoldICSLines(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	return slices.ContainsFunc(times, t.Equal)
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "context"
+
+const (
+	LineMessageTypeText = "text"
+	LineMessageTypeFlex = "flex"
+)
+
+type LineMessage struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	AltText  string `json:"altText,omitempty"`
+	Contents any    `json:"contents,omitempty"`
+}
+
+// LineClient sends messages through the Line Messaging API.
+type LineClient interface {
+	ReplyMessage(ctx context.Context, replyToken string, messages []LineMessage) error
+	PushMessage(ctx context.Context, to string, messages []LineMessage) error
+}
+
+func NewLineTextMessage(text string) LineMessage {
+	return LineMessage{Type: LineMessageTypeText, Text: text}
+}
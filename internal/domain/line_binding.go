@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// LineBindingCode is a one-time code a member sends to the bot to link their
+// Line account to their user record.
+type LineBindingCode struct {
+	Code      string    `json:"code"`
+	UserID    int64     `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var ErrInvalidBindingCode = errors.New("invalid or expired binding code")
+
+const (
+	LineBindingCodeLength = 8
+	LineBindingCodeTTL    = 10 * time.Minute
+)
+
+type LineBindingCodeRepository interface {
+	// Save stores the code, replacing any code previously issued to the same user.
+	Save(ctx context.Context, code *LineBindingCode) error
+	// Consume deletes and returns the code so it cannot be used twice.
+	Consume(ctx context.Context, code string) (*LineBindingCode, error)
+}
+
+func (c *LineBindingCode) IsExpired(now time.Time) bool {
+	return !now.Before(c.ExpiresAt)
+}
@@ -0,0 +1,121 @@
+package domain
+
+const (
+	flexTypeBubble = "bubble"
+	flexTypeBox    = "box"
+	flexTypeText   = "text"
+	flexTypeButton = "button"
+
+	flexLayoutVertical = "vertical"
+	flexLayoutBaseline = "baseline"
+
+	flexActionPostback = "postback"
+
+	flexLabelFlex = 2
+	flexValueFlex = 5
+
+	flexColorMuted = "#888888"
+)
+
+// FlexBubble is the "contents" of a Line Flex Message with a single card.
+type FlexBubble struct {
+	Type   string         `json:"type"`
+	Header *FlexComponent `json:"header,omitempty"`
+	Body   *FlexComponent `json:"body,omitempty"`
+	Footer *FlexComponent `json:"footer,omitempty"`
+}
+
+type FlexComponent struct {
+	Type     string          `json:"type"`
+	Layout   string          `json:"layout,omitempty"`
+	Contents []FlexComponent `json:"contents,omitempty"`
+	Text     string          `json:"text,omitempty"`
+	Size     string          `json:"size,omitempty"`
+	Weight   string          `json:"weight,omitempty"`
+	Color    string          `json:"color,omitempty"`
+	Wrap     bool            `json:"wrap,omitempty"`
+	Flex     int             `json:"flex,omitempty"`
+	Spacing  string          `json:"spacing,omitempty"`
+	Style    string          `json:"style,omitempty"`
+	Height   string          `json:"height,omitempty"`
+	Action   *FlexAction     `json:"action,omitempty"`
+}
+
+type FlexAction struct {
+	Type        string `json:"type"`
+	Label       string `json:"label"`
+	Data        string `json:"data,omitempty"`
+	DisplayText string `json:"displayText,omitempty"`
+}
+
+// FlexCardBuilder assembles the title / fields / buttons card layout used by
+// all bot replies.
+type FlexCardBuilder struct {
+	title    string
+	subtitle string
+	body     []FlexComponent
+	buttons  []FlexComponent
+}
+
+func NewFlexCard(title string) *FlexCardBuilder {
+	return &FlexCardBuilder{title: title}
+}
+
+func (b *FlexCardBuilder) Subtitle(subtitle string) *FlexCardBuilder {
+	b.subtitle = subtitle
+	return b
+}
+
+func (b *FlexCardBuilder) Text(text string) *FlexCardBuilder {
+	b.body = append(b.body, FlexComponent{Type: flexTypeText, Text: text, Size: "sm", Wrap: true})
+	return b
+}
+
+// Field adds a "label  value" row, e.g. 時間 / 地點 / 崗位.
+func (b *FlexCardBuilder) Field(label, value string) *FlexCardBuilder {
+	b.body = append(b.body, FlexComponent{
+		Type:    flexTypeBox,
+		Layout:  flexLayoutBaseline,
+		Spacing: "sm",
+		Contents: []FlexComponent{
+			{Type: flexTypeText, Text: label, Size: "sm", Color: flexColorMuted, Flex: flexLabelFlex},
+			{Type: flexTypeText, Text: value, Size: "sm", Wrap: true, Flex: flexValueFlex},
+		},
+	})
+	return b
+}
+
+func (b *FlexCardBuilder) PostbackButton(label, data string) *FlexCardBuilder {
+	b.buttons = append(b.buttons, FlexComponent{
+		Type:   flexTypeButton,
+		Style:  "secondary",
+		Height: "sm",
+		Action: &FlexAction{Type: flexActionPostback, Label: label, Data: data, DisplayText: label},
+	})
+	return b
+}
+
+func (b *FlexCardBuilder) Bubble() FlexBubble {
+	header := []FlexComponent{{Type: flexTypeText, Text: b.title, Size: "lg", Weight: "bold", Wrap: true}}
+	if b.subtitle != "" {
+		header = append(header, FlexComponent{Type: flexTypeText, Text: b.subtitle, Size: "xs", Color: flexColorMuted})
+	}
+
+	bubble := FlexBubble{
+		Type:   flexTypeBubble,
+		Header: &FlexComponent{Type: flexTypeBox, Layout: flexLayoutVertical, Contents: header},
+	}
+	if len(b.body) > 0 {
+		bubble.Body = &FlexComponent{Type: flexTypeBox, Layout: flexLayoutVertical, Spacing: "md", Contents: b.body}
+	}
+	if len(b.buttons) > 0 {
+		bubble.Footer = &FlexComponent{Type: flexTypeBox, Layout: flexLayoutVertical, Spacing: "sm", Contents: b.buttons}
+	}
+	return bubble
+}
+
+// Message wraps the card in a Flex Message; altText is what notifications and
+// chat lists show.
+func (b *FlexCardBuilder) Message(altText string) LineMessage {
+	return LineMessage{Type: LineMessageTypeFlex, AltText: altText, Contents: b.Bubble()}
+}
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type NotificationChannel string
+
+const (
+	NotificationChannelLine  NotificationChannel = "line"
+	NotificationChannelEmail NotificationChannel = "email"
+	NotificationChannelInApp NotificationChannel = "in_app"
+)
+
+type NotificationEvent string
+
+const (
+	NotificationEventLineBound   NotificationEvent = "line_bound"
+	NotificationEventLineUnbound NotificationEvent = "line_unbound"
+)
+
+type NotificationStatus string
+
+const (
+	NotificationStatusPending NotificationStatus = "pending"
+	NotificationStatusSent    NotificationStatus = "sent"
+	NotificationStatusDead    NotificationStatus = "dead"
+)
+
+// Notification is one outbox entry: a message for one user on one channel.
+type Notification struct {
+	ID            int64               `json:"id"`
+	UserID        int64               `json:"user_id"`
+	Event         NotificationEvent   `json:"event"`
+	Channel       NotificationChannel `json:"channel"`
+	Title         string              `json:"title"`
+	Body          string              `json:"body"`
+	Status        NotificationStatus  `json:"status"`
+	Attempts      int                 `json:"attempts"`
+	LastError     string              `json:"last_error,omitempty"`
+	NextAttemptAt time.Time           `json:"next_attempt_at"`
+	SentAt        *time.Time          `json:"sent_at,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	// LockedUntil is the lease ClaimDue took, nil when the notification isn't
+	// leased. It identifies the claim, so UpdateDelivery can tell whether
+	// another worker has claimed the row since.
+	LockedUntil *time.Time `json:"-"`
+}
+
+// InAppNotification is a message shown in the user's inbox in the web app.
+type InAppNotification struct {
+	ID        int64             `json:"id"`
+	UserID    int64             `json:"user_id"`
+	Event     NotificationEvent `json:"event"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// Cursor sorts of the notification listings. Dead letters are listed newest
+// first by ID, inbox notifications newest first by creation time.
+const (
+	NotificationSortNewest      = "-id"
+	InAppNotificationSortNewest = "-created_at"
+)
+
+// NotificationPage is one page of an outbox listing. NextCursor is empty on
+// the last page.
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	Count         int             `json:"count"`
+	Total         int             `json:"total"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+}
+
+// InAppNotificationPage is one page of a user's inbox. NextCursor is empty on
+// the last page.
+type InAppNotificationPage struct {
+	Notifications []*InAppNotification `json:"notifications"`
+	Count         int                  `json:"count"`
+	Total         int                  `json:"total"`
+	NextCursor    string               `json:"next_cursor,omitempty"`
+}
+
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrNotificationNotDead  = errors.New("notification is not in the dead-letter list")
+	// ErrNotificationLeaseLost means the lease expired and another worker
+	// claimed the notification, so this worker's result must be dropped.
+	ErrNotificationLeaseLost = errors.New("notification lease lost")
+	// ErrNotificationUndeliverable marks failures that retrying cannot fix, e.g.
+	// the user has no Line account bound, so the notification is dead-lettered.
+	ErrNotificationUndeliverable = errors.New("notification cannot be delivered")
+)
+
+const (
+	MaxNotificationAttempts = 6
+	notificationBaseBackoff = 30 * time.Second
+	notificationMaxBackoff  = time.Hour
+)
+
+type NotificationRepository interface {
+	Enqueue(ctx context.Context, notifications []*Notification) error
+	GetByID(ctx context.Context, id int64) (*Notification, error)
+	// ClaimDue leases up to limit pending notifications that are due at now,
+	// hiding them from other workers until lockedUntil.
+	ClaimDue(ctx context.Context, now time.Time, limit int, lockedUntil time.Time) ([]*Notification, error)
+	// UpdateDelivery saves the delivery state and releases the lease. It
+	// returns ErrNotificationLeaseLost unless the row still holds
+	// notification.LockedUntil.
+	UpdateDelivery(ctx context.Context, notification *Notification) error
+	// ListByStatus returns notifications newest first by ID. page.After is
+	// the last notification of the previous page.
+	ListByStatus(ctx context.Context, status NotificationStatus, page Page) ([]*Notification, error)
+	CountByStatus(ctx context.Context, status NotificationStatus) (int, error)
+}
+
+type InAppNotificationRepository interface {
+	Create(ctx context.Context, notification *InAppNotification) error
+	// ListByUser returns notifications newest first, ties broken by ID.
+	// page.After is the last notification of the previous page.
+	ListByUser(ctx context.Context, userID int64, page Page) ([]*InAppNotification, error)
+	CountByUser(ctx context.Context, userID int64) (int, error)
+}
+
+// Notifier delivers notifications over one channel.
+type Notifier interface {
+	Channel() NotificationChannel
+	Send(ctx context.Context, user *User, notification *Notification) error
+}
+
+// DefaultChannels lists the channels an event is delivered on.
+func (e NotificationEvent) DefaultChannels() []NotificationChannel {
+	switch e {
+	case NotificationEventLineBound, NotificationEventLineUnbound:
+		// The bot already answers in the chat, so account changes are
+		// confirmed out of band.
+		return []NotificationChannel{NotificationChannelEmail, NotificationChannelInApp}
+	default:
+		return []NotificationChannel{NotificationChannelLine, NotificationChannelInApp}
+	}
+}
+
+// NotificationBackoff returns the delay before retrying after the given
+// number of failed attempts.
+func NotificationBackoff(attempts int) time.Duration {
+	backoff := notificationBaseBackoff
+	for i := 1; i < attempts && backoff < notificationMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, notificationMaxBackoff)
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NotificationPreferences holds a user's delivery choices. Events and channels
+// that are not listed follow the event's default channels.
+type NotificationPreferences struct {
+	Channels   map[NotificationEvent]map[NotificationChannel]bool `json:"channels,omitempty"`
+	QuietHours *QuietHours                                        `json:"quiet_hours,omitempty"`
+}
+
+// QuietHours is a daily window, in the user's timezone, during which
+// notifications that alert the user's device are held back. Start and End are
+// "HH:MM"; a window whose end is before its start runs past midnight.
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
+}
+
+var ErrInvalidNotificationPreferences = errors.New("invalid notification preferences")
+
+const (
+	clockLayout    = "15:04"
+	minutesPerHour = 60
+)
+
+// allNotificationChannels fixes the order channels are returned in.
+func allNotificationChannels() []NotificationChannel {
+	return []NotificationChannel{NotificationChannelLine, NotificationChannelEmail, NotificationChannelInApp}
+}
+
+func (c NotificationChannel) IsValid() bool {
+	switch c {
+	case NotificationChannelLine, NotificationChannelEmail, NotificationChannelInApp:
+		return true
+	default:
+		return false
+	}
+}
+
+// Interrupts reports whether the channel alerts the user's device, and so
+// respects quiet hours. The in-app inbox is only read when the user opens it.
+func (c NotificationChannel) Interrupts() bool {
+	return c != NotificationChannelInApp
+}
+
+func (e NotificationEvent) IsValid() bool {
+	switch e {
+	case NotificationEventLineBound, NotificationEventLineUnbound:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p NotificationPreferences) Validate() error {
+	for event, channels := range p.Channels {
+		if !event.IsValid() {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidNotificationPreferences, event)
+		}
+		for channel := range channels {
+			if !channel.IsValid() {
+				return fmt.Errorf("%w: unknown channel %q", ErrInvalidNotificationPreferences, channel)
+			}
+		}
+	}
+
+	if p.QuietHours != nil {
+		return p.QuietHours.Validate()
+	}
+	return nil
+}
+
+// ChannelsFor lists the channels event is delivered on for this user.
+func (p NotificationPreferences) ChannelsFor(event NotificationEvent) []NotificationChannel {
+	enabled := make(map[NotificationChannel]bool)
+	for _, channel := range event.DefaultChannels() {
+		enabled[channel] = true
+	}
+	for channel, on := range p.Channels[event] {
+		enabled[channel] = on
+	}
+
+	var channels []NotificationChannel
+	for _, channel := range allNotificationChannels() {
+		if enabled[channel] {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// QuietUntil reports whether a notification on channel must wait at now, and
+// if so until when.
+func (p NotificationPreferences) QuietUntil(channel NotificationChannel, now time.Time) (time.Time, bool) {
+	if p.QuietHours == nil || !channel.Interrupts() {
+		return time.Time{}, false
+	}
+	return p.QuietHours.Until(now)
+}
+
+// Location returns the timezone of the quiet hours, the only timezone a user
+// sets, or fallback when there is none.
+func (p NotificationPreferences) Location(fallback *time.Location) *time.Location {
+	if p.QuietHours == nil || p.QuietHours.Timezone == "" {
+		return fallback
+	}
+	if loc, err := time.LoadLocation(p.QuietHours.Timezone); err == nil {
+		return loc
+	}
+	return fallback
+}
+
+func (q *QuietHours) Validate() error {
+	if _, err := time.LoadLocation(q.Timezone); err != nil || q.Timezone == "" {
+		return fmt.Errorf("%w: unknown timezone %q", ErrInvalidNotificationPreferences, q.Timezone)
+	}
+
+	start, startErr := parseClock(q.Start)
+	end, endErr := parseClock(q.End)
+	if startErr != nil || endErr != nil {
+		return fmt.Errorf("%w: quiet hours must be HH:MM", ErrInvalidNotificationPreferences)
+	}
+	if start == end {
+		return fmt.Errorf("%w: quiet hours start and end must differ", ErrInvalidNotificationPreferences)
+	}
+	return nil
+}
+
+// Until reports whether now falls inside the quiet window, and if so when the
+// window ends.
+func (q *QuietHours) Until(now time.Time) (time.Time, bool) {
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start, startErr := parseClock(q.Start)
+	end, endErr := parseClock(q.End)
+	if startErr != nil || endErr != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	minute := local.Hour()*minutesPerHour + local.Minute()
+
+	var quiet bool
+	if start < end {
+		quiet = minute >= start && minute < end
+	} else {
+		quiet = minute >= start || minute < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	day := local
+	if minute >= end {
+		day = local.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), end/minutesPerHour, end%minutesPerHour, 0, 0, loc), true
+}
+
+// parseClock converts "HH:MM" to minutes after midnight.
+func parseClock(value string) (int, error) {
+	clock, err := time.Parse(clockLayout, value)
+	if err != nil {
+		return 0, err
+	}
+	return clock.Hour()*minutesPerHour + clock.Minute(), nil
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks the last row of a page in a list ordered by Sort, with the ID
+// breaking ties. Lists page with it by keyset, so rows inserted while paging
+// don't cause duplicates or skips. Key holds the sort value for orderings
+// other than creation time.
+type Cursor struct {
+	Sort      string    `json:"s,omitempty"`
+	CreatedAt time.Time `json:"t"`
+	Key       string    `json:"k,omitempty"`
+	ID        int64     `json:"id"`
+}
+
+// Page selects part of a list: the rows after After if it is set, otherwise
+// the rows after skipping Offset.
+type Page struct {
+	Limit  int
+	Offset int
+	After  *Cursor
+}
+
+// Encode returns the cursor as an opaque string for API responses.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(value string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err = json.Unmarshal(data, &cursor); err != nil || cursor.CreatedAt.IsZero() || cursor.ID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ScheduleIntent string
+
+const (
+	ScheduleIntentMine      ScheduleIntent = "my_schedule"
+	ScheduleIntentTeam      ScheduleIntent = "team_schedule"
+	ScheduleIntentCoServers ScheduleIntent = "co_servers"
+)
+
+// ScheduleQuery is a roster question resolved to an intent and a date range.
+// The range covers whole days in the caller's timezone, from From up to but
+// not including To.
+type ScheduleQuery struct {
+	Intent ScheduleIntent `json:"intent"`
+	From   time.Time      `json:"from"`
+	To     time.Time      `json:"to"`
+}
+
+var ErrScheduleQueryNotUnderstood = errors.New("schedule query not understood")
+
+// DefaultScheduleQueryDays is the range used when a question names no date.
+const DefaultScheduleQueryDays = 28
+
+const (
+	daysPerWeek   = 7
+	monthsPerYear = 12
+)
+
+var (
+	scheduleKeywordRegex = regexp.MustCompile(`服事|服侍|事奉|安排|排班|班表|輪值|值班|輪到`)
+	monthDayRegex        = regexp.MustCompile(`(\d{1,2})(?:/|月)(\d{1,2})(?:日|號)?`)
+	monthRegex           = regexp.MustCompile(`(\d{1,2})月`)
+	// Alternatives are tried in order, so 下下 must come before 下.
+	weekRegex = regexp.MustCompile(`(上|這|本|下下|下)個?(?:週|周|禮拜|星期)([一二三四五六日天])?`)
+)
+
+// ParseScheduleQuery resolves a Chinese question such as "我下週有服事嗎？" or
+// "幫我看一下 10 月的安排" relative to now. Dates are resolved in now's
+// location, and a month or day that has already passed this year refers to
+// next year.
+func ParseScheduleQuery(text string, now time.Time) (*ScheduleQuery, error) {
+	text = normalizeScheduleText(text)
+	if !scheduleKeywordRegex.MatchString(text) {
+		return nil, ErrScheduleQueryNotUnderstood
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	from, to, err := parseScheduleRange(text, today)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScheduleQuery{Intent: parseScheduleIntent(text), From: from, To: to}, nil
+}
+
+func parseScheduleIntent(text string) ScheduleIntent {
+	together := strings.Contains(text, "一起") || strings.Contains(text, "跟我") ||
+		strings.Contains(text, "和我") || strings.Contains(text, "同工")
+	switch {
+	case together:
+		return ScheduleIntentCoServers
+	case strings.Contains(text, "誰") || strings.Contains(text, "團隊") || strings.Contains(text, "大家") ||
+		strings.Contains(text, "所有人") || strings.Contains(text, "全部"):
+		return ScheduleIntentTeam
+	default:
+		return ScheduleIntentMine
+	}
+}
+
+func parseScheduleRange(text string, today time.Time) (time.Time, time.Time, error) {
+	if match := monthDayRegex.FindStringSubmatch(text); match != nil {
+		day, ok := resolveMonthDay(match[1], match[2], today)
+		if !ok {
+			return time.Time{}, time.Time{}, ErrScheduleQueryNotUnderstood
+		}
+		return day, day.AddDate(0, 0, 1), nil
+	}
+
+	if match := weekRegex.FindStringSubmatch(text); match != nil {
+		monday := startOfWeek(today).AddDate(0, 0, daysPerWeek*weekOffset(match[1]))
+		if match[2] != "" {
+			day := monday.AddDate(0, 0, weekdayIndex(match[2]))
+			return day, day.AddDate(0, 0, 1), nil
+		}
+		return monday, monday.AddDate(0, 0, daysPerWeek), nil
+	}
+
+	switch {
+	case strings.Contains(text, "今天"), strings.Contains(text, "今日"):
+		return today, today.AddDate(0, 0, 1), nil
+	case strings.Contains(text, "明天"), strings.Contains(text, "明日"):
+		return today.AddDate(0, 0, 1), today.AddDate(0, 0, 2), nil
+	case strings.Contains(text, "後天"):
+		return today.AddDate(0, 0, 2), today.AddDate(0, 0, 3), nil
+	}
+
+	firstOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+	switch {
+	case strings.Contains(text, "這個月"), strings.Contains(text, "本月"), strings.Contains(text, "這月"):
+		return firstOfMonth, firstOfMonth.AddDate(0, 1, 0), nil
+	case strings.Contains(text, "下個月"), strings.Contains(text, "下月"):
+		return firstOfMonth.AddDate(0, 1, 0), firstOfMonth.AddDate(0, 2, 0), nil
+	case strings.Contains(text, "上個月"), strings.Contains(text, "上月"):
+		return firstOfMonth.AddDate(0, -1, 0), firstOfMonth, nil
+	}
+
+	if match := monthRegex.FindStringSubmatch(text); match != nil {
+		month, _ := strconv.Atoi(match[1])
+		if month < 1 || month > monthsPerYear {
+			return time.Time{}, time.Time{}, ErrScheduleQueryNotUnderstood
+		}
+		year := today.Year()
+		if time.Month(month) < today.Month() {
+			year++
+		}
+		start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, today.Location())
+		return start, start.AddDate(0, 1, 0), nil
+	}
+
+	return today, today.AddDate(0, 0, DefaultScheduleQueryDays), nil
+}
+
+func resolveMonthDay(monthText, dayText string, today time.Time) (time.Time, bool) {
+	month, _ := strconv.Atoi(monthText)
+	day, _ := strconv.Atoi(dayText)
+
+	date := time.Date(today.Year(), time.Month(month), day, 0, 0, 0, 0, today.Location())
+	if date.Before(today) {
+		date = time.Date(today.Year()+1, time.Month(month), day, 0, 0, 0, 0, today.Location())
+	}
+	// time.Date normalises out-of-range values such as 2/30; reject them.
+	if int(date.Month()) != month || date.Day() != day {
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// startOfWeek returns the Monday of the week containing day.
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + daysPerWeek - 1) % daysPerWeek
+	return day.AddDate(0, 0, -offset)
+}
+
+// weekOffset turns 上, 這/本, 下 and 下下 into a number of weeks from now.
+func weekOffset(prefix string) int {
+	return strings.Count(prefix, "下") - strings.Count(prefix, "上")
+}
+
+// weekdayIndex counts days from Monday.
+func weekdayIndex(name string) int {
+	return strings.Index("一二三四五六日", strings.ReplaceAll(name, "天", "日")) / len("一")
+}
+
+// normalizeScheduleText converts full-width digits and slashes and drops
+// whitespace, so "１０／２０" and "10 月" match like "10/20" and "10月".
+func normalizeScheduleText(text string) string {
+	text = strings.Map(func(r rune) rune {
+		switch {
+		case r >= '０' && r <= '９':
+			return '0' + (r - '０')
+		case r == '／':
+			return '/'
+		default:
+			return r
+		}
+	}, text)
+	return strings.Join(strings.Fields(text), "")
+}
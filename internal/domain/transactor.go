@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// Transactor runs fn in a transaction; repositories called with the context
+// passed to fn take part in it.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
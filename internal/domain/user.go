@@ -3,16 +3,19 @@ package domain
 import (
 	"context"
 	"errors"
+	"fmt"
 	"regexp"
 	"time"
 )
 
 type User struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                      int64                   `json:"id"`
+	Name                    string                  `json:"name"`
+	Email                   string                  `json:"email"`
+	LineUserID              string                  `json:"line_user_id,omitempty"`
+	NotificationPreferences NotificationPreferences `json:"notification_preferences"`
+	CreatedAt               time.Time               `json:"created_at"`
+	UpdatedAt               time.Time               `json:"updated_at"`
 }
 
 type CreateUserRequest struct {
@@ -26,11 +29,14 @@ type UpdateUserRequest struct {
 }
 
 var (
-	ErrUserNotFound    = errors.New("user not found")
-	ErrUserExists      = errors.New("user already exists")
-	ErrInvalidUserData = errors.New("invalid user data")
-	ErrEmptyName       = errors.New("name cannot be empty")
-	ErrInvalidEmail    = errors.New("invalid email format")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserExists        = errors.New("user already exists")
+	ErrInvalidUserData   = errors.New("invalid user data")
+	ErrEmptyName         = errors.New("name cannot be empty")
+	ErrInvalidEmail      = errors.New("invalid email format")
+	ErrLineAccountBound  = errors.New("line account already bound to another user")
+	ErrLineNotBound      = errors.New("line account not bound")
+	ErrInvalidUserFilter = errors.New("invalid user filter")
 )
 
 const (
@@ -40,6 +46,56 @@ const (
 	MaxEmailLength = 254
 )
 
+// UserFilter narrows a user listing. Zero fields don't filter.
+type UserFilter struct {
+	// Query matches users whose name or email contains it, ignoring ASCII case.
+	Query string
+	// Name matches users whose name contains it, ignoring ASCII case.
+	Name string
+	// CreatedFrom and CreatedTo bound the creation time; CreatedTo is exclusive.
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+}
+
+// UserSort orders a user listing; a leading "-" means descending. Ties are
+// broken by ID in the same direction.
+type UserSort string
+
+const (
+	UserSortNewest   UserSort = "-created_at"
+	UserSortOldest   UserSort = "created_at"
+	UserSortName     UserSort = "name"
+	UserSortNameDesc UserSort = "-name"
+)
+
+func (s UserSort) IsValid() bool {
+	switch s {
+	case UserSortNewest, UserSortOldest, UserSortName, UserSortNameDesc:
+		return true
+	default:
+		return false
+	}
+}
+
+// CursorAfter returns the cursor that continues the listing after user.
+func (s UserSort) CursorAfter(user *User) Cursor {
+	cursor := Cursor{Sort: string(s), CreatedAt: user.CreatedAt, ID: user.ID}
+	switch s {
+	case UserSortName, UserSortNameDesc:
+		cursor.Key = user.Name
+	case UserSortNewest, UserSortOldest:
+	}
+	return cursor
+}
+
+// UserPage is one page of a user listing. NextCursor is empty on the last page.
+type UserPage struct {
+	Users      []*User `json:"users"`
+	Count      int     `json:"count"`
+	Total      int     `json:"total"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
+
 var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 
 type UserRepository interface {
@@ -48,7 +104,13 @@ type UserRepository interface {
 	Create(ctx context.Context, user *User) (*User, error)
 	Update(ctx context.Context, user *User) (*User, error)
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, limit, offset int) ([]*User, error)
+	// List returns users in sort order. page.After comes from sort.CursorAfter.
+	List(ctx context.Context, filter UserFilter, sort UserSort, page Page) ([]*User, error)
+	Count(ctx context.Context, filter UserFilter) (int, error)
+	GetByLineUserID(ctx context.Context, lineUserID string) (*User, error)
+	BindLineAccount(ctx context.Context, userID int64, lineUserID string) error
+	UnbindLineAccount(ctx context.Context, userID int64) error
+	UpdateNotificationPreferences(ctx context.Context, userID int64, preferences NotificationPreferences) error
 }
 
 func (u *User) Validate() error {
@@ -71,6 +133,19 @@ func (req *CreateUserRequest) Validate() error {
 	return nil
 }
 
+func (f UserFilter) Validate() error {
+	if len(f.Query) > MaxEmailLength {
+		return fmt.Errorf("%w: query is too long", ErrInvalidUserFilter)
+	}
+	if len(f.Name) > MaxNameLength {
+		return fmt.Errorf("%w: name is too long", ErrInvalidUserFilter)
+	}
+	if !f.CreatedFrom.IsZero() && !f.CreatedTo.IsZero() && !f.CreatedTo.After(f.CreatedFrom) {
+		return fmt.Errorf("%w: created_to must be after created_from", ErrInvalidUserFilter)
+	}
+	return nil
+}
+
 func validateName(name string) error {
 	if len(name) < MinNameLength {
 		return ErrEmptyName
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/usecase"
+)
+
+const defaultBusyTimeDays = 30
+
+type BusyTimeHandler struct {
+	usecase *usecase.BusyTimeUsecase
+}
+
+func NewBusyTimeHandler(usecase *usecase.BusyTimeUsecase) *BusyTimeHandler {
+	return &BusyTimeHandler{usecase: usecase}
+}
+
+func (h *BusyTimeHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/users/{id}/busy-sources", h.handleSources)
+	mux.HandleFunc("/users/{id}/busy-sources/{sourceID}", h.handleSource)
+	mux.HandleFunc("/users/{id}/busy-times", h.handleBusyTimes)
+}
+
+func (h *BusyTimeHandler) handleSources(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		sources, listErr := h.usecase.ListSources(ctx, id)
+		if listErr != nil {
+			handleError(w, listErr)
+			return
+		}
+		writeJSONResponse(w, http.StatusOK, map[string]any{
+			"sources": sources,
+			"count":   len(sources),
+		})
+
+	case http.MethodPost:
+		source, createErr := h.createSource(ctx, w, r, id)
+		if createErr != nil {
+			handleError(w, createErr)
+			return
+		}
+		writeJSONResponse(w, http.StatusCreated, source)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// createSource imports an ICS file sent as text/calendar, or subscribes to the
+// URL in a JSON body.
+func (h *BusyTimeHandler) createSource(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	userID int64,
+) (*domain.BusySource, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/calendar" {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, domain.MaxCalendarSize))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, errors.Join(domain.ErrInvalidCalendar, err)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return h.usecase.ImportCalendar(ctx, userID, data)
+	}
+
+	var req struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.URL == "" {
+		return nil, domain.ErrInvalidCalendarURL
+	}
+	return h.usecase.Subscribe(ctx, userID, req.URL)
+}
+
+func (h *BusyTimeHandler) handleSource(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	sourceID, err := strconv.ParseInt(r.PathValue("sourceID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid source ID", http.StatusBadRequest)
+		return
+	}
+
+	if deleteErr := h.usecase.DeleteSource(ctx, id, sourceID); deleteErr != nil {
+		handleError(w, deleteErr)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleBusyTimes lists busy blocks between the from and to dates
+// (YYYY-MM-DD, to exclusive), by default the next 30 days.
+func (h *BusyTimeHandler) handleBusyTimes(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if value := r.URL.Query().Get("from"); value != "" {
+		if from, err = time.ParseInLocation(filterDateLayout, value, now.Location()); err != nil {
+			http.Error(w, "Invalid from date", http.StatusBadRequest)
+			return
+		}
+	}
+	to := from.AddDate(0, 0, defaultBusyTimeDays)
+	if value := r.URL.Query().Get("to"); value != "" {
+		if to, err = time.ParseInLocation(filterDateLayout, value, now.Location()); err != nil || !to.After(from) {
+			http.Error(w, "Invalid to date", http.StatusBadRequest)
+			return
+		}
+	}
+
+	blocks, err := h.usecase.ListBusy(ctx, id, from, to)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, map[string]any{
+		"busy_times": blocks,
+		"count":      len(blocks),
+	})
+}
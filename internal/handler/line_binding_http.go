@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"ministry-scheduler/internal/usecase"
+)
+
+const lineOAMessageURL = "https://line.me/R/oaMessage/"
+
+type lineBindingResponse struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Link      string    `json:"link,omitempty"`
+}
+
+type LineBindingHandler struct {
+	usecase *usecase.LineBindingUsecase
+	botID   string
+}
+
+// NewLineBindingHandler creates the handler. botID is the bot's basic ID
+// (e.g. "@abc1234"); when set, responses include a link that opens the bot
+// chat with the binding message already filled in.
+func NewLineBindingHandler(usecase *usecase.LineBindingUsecase, botID string) *LineBindingHandler {
+	return &LineBindingHandler{usecase: usecase, botID: botID}
+}
+
+// RegisterRoutes registers the binding endpoints. They don't authenticate the
+// caller: a code from POST binds whoever sends it to user {id}, so the routes
+// must sit behind authentication that checks the caller is that user.
+func (h *LineBindingHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/users/{id}/line-binding", h.handleLineBinding)
+}
+
+func (h *LineBindingHandler) handleLineBinding(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		h.generateCode(ctx, w, id)
+	case http.MethodDelete:
+		h.unbind(ctx, w, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *LineBindingHandler) generateCode(ctx context.Context, w http.ResponseWriter, userID int64) {
+	code, err := h.usecase.GenerateCode(ctx, userID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	resp := lineBindingResponse{Code: code.Code, ExpiresAt: code.ExpiresAt}
+	if h.botID != "" {
+		resp.Link = lineOAMessageURL + url.PathEscape(h.botID) + "/?" + url.PathEscape(usecase.LineBindMessage(code.Code))
+	}
+
+	writeJSONResponse(w, http.StatusCreated, resp)
+}
+
+func (h *LineBindingHandler) unbind(ctx context.Context, w http.ResponseWriter, userID int64) {
+	if err := h.usecase.Unbind(ctx, userID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/usecase"
+)
+
+const (
+	lineSignatureHeader = "X-Line-Signature"
+	maxWebhookBodySize  = 1 << 20
+
+	lineEventMessage  = "message"
+	lineEventPostback = "postback"
+	lineEventFollow   = "follow"
+
+	lineModeStandby = "standby"
+)
+
+type lineWebhookPayload struct {
+	Events []lineEvent `json:"events"`
+}
+
+type lineEvent struct {
+	Type       string               `json:"type"`
+	Mode       string               `json:"mode"`
+	ReplyToken string               `json:"replyToken"`
+	Source     lineEventSource      `json:"source"`
+	Message    *lineMessageContent  `json:"message,omitempty"`
+	Postback   *linePostbackContent `json:"postback,omitempty"`
+}
+
+type lineEventSource struct {
+	UserID string `json:"userId"`
+}
+
+type lineMessageContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type linePostbackContent struct {
+	Data string `json:"data"`
+}
+
+type LineWebhookHandler struct {
+	channelSecret []byte
+	usecase       *usecase.LineBotUsecase
+	client        domain.LineClient
+	logger        *slog.Logger
+}
+
+func NewLineWebhookHandler(
+	channelSecret string,
+	usecase *usecase.LineBotUsecase,
+	client domain.LineClient,
+	logger *slog.Logger,
+) *LineWebhookHandler {
+	return &LineWebhookHandler{
+		channelSecret: []byte(channelSecret),
+		usecase:       usecase,
+		client:        client,
+		logger:        logger,
+	}
+}
+
+func (h *LineWebhookHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/line/webhook", h.handleWebhook)
+}
+
+func (h *LineWebhookHandler) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !h.validSignature(r.Header.Get(lineSignatureHeader), body) {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload lineWebhookPayload
+	if unmarshalErr := json.Unmarshal(body, &payload); unmarshalErr != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	// Line redelivers events on non-2xx responses, so failures are logged
+	// per event instead of failing the whole batch.
+	for _, event := range payload.Events {
+		h.dispatch(ctx, event)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *LineWebhookHandler) dispatch(ctx context.Context, event lineEvent) {
+	if event.Mode == lineModeStandby || event.Source.UserID == "" {
+		return
+	}
+
+	var (
+		messages []domain.LineMessage
+		err      error
+	)
+	switch event.Type {
+	case lineEventMessage:
+		if event.Message == nil || event.Message.Type != domain.LineMessageTypeText {
+			return
+		}
+		messages, err = h.usecase.HandleTextMessage(ctx, event.Source.UserID, event.Message.Text)
+	case lineEventPostback:
+		if event.Postback == nil {
+			return
+		}
+		messages, err = h.usecase.HandlePostback(ctx, event.Source.UserID, event.Postback.Data)
+	case lineEventFollow:
+		messages, err = h.usecase.HandleFollow(ctx, event.Source.UserID)
+	default:
+		return
+	}
+
+	if err != nil {
+		h.logger.ErrorContext(ctx, "failed to handle line event", "type", event.Type, "error", err)
+		return
+	}
+	if len(messages) == 0 || event.ReplyToken == "" {
+		return
+	}
+
+	if replyErr := h.client.ReplyMessage(ctx, event.ReplyToken, messages); replyErr != nil {
+		h.logger.ErrorContext(ctx, "failed to reply to line event", "type", event.Type, "error", replyErr)
+	}
+}
+
+func (h *LineWebhookHandler) validSignature(signature string, body []byte) bool {
+	expected, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(expected) == 0 {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, h.channelSecret)
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/usecase"
+)
+
+type NotificationHandler struct {
+	usecase *usecase.NotificationUsecase
+}
+
+func NewNotificationHandler(usecase *usecase.NotificationUsecase) *NotificationHandler {
+	return &NotificationHandler{usecase: usecase}
+}
+
+func (h *NotificationHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/users/{id}/notifications", h.handleUserNotifications)
+	mux.HandleFunc("/users/{id}/notification-preferences", h.handlePreferences)
+	mux.HandleFunc("/admin/notifications/dead-letters", h.handleDeadLetters)
+	mux.HandleFunc("/admin/notifications/{id}/retry", h.handleRetry)
+}
+
+func (h *NotificationHandler) handleUserNotifications(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	page, err := parsePage(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	notifications, err := h.usecase.ListInApp(ctx, id, page)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, notifications)
+}
+
+func (h *NotificationHandler) handlePreferences(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getPreferences(ctx, w, id)
+	case http.MethodPut:
+		h.updatePreferences(ctx, w, r, id)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *NotificationHandler) getPreferences(ctx context.Context, w http.ResponseWriter, userID int64) {
+	preferences, err := h.usecase.GetPreferences(ctx, userID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, preferences)
+}
+
+func (h *NotificationHandler) updatePreferences(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	userID int64,
+) {
+	var req domain.NotificationPreferences
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	preferences, err := h.usecase.UpdatePreferences(ctx, userID, &req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, preferences)
+}
+
+func (h *NotificationHandler) handleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	page, err := parsePage(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	notifications, err := h.usecase.ListDeadLetters(ctx, page)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, notifications)
+}
+
+func (h *NotificationHandler) handleRetry(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	notification, err := h.usecase.Retry(ctx, id)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, notification)
+}
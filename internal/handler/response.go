@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// Helpers for response handling shared by all handlers.
+func writeJSONResponse(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if encodeErr := json.NewEncoder(w).Encode(data); encodeErr != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+func handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound), errors.Is(err, domain.ErrLineNotBound),
+		errors.Is(err, domain.ErrNotificationNotFound), errors.Is(err, domain.ErrBusySourceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrUserExists), errors.Is(err, domain.ErrLineAccountBound),
+		errors.Is(err, domain.ErrNotificationNotDead):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrEmptyName), errors.Is(err, domain.ErrInvalidEmail),
+		errors.Is(err, domain.ErrInvalidNotificationPreferences), errors.Is(err, domain.ErrScheduleQueryNotUnderstood),
+		errors.Is(err, domain.ErrInvalidCalendar), errors.Is(err, domain.ErrInvalidCalendarURL),
+		errors.Is(err, domain.ErrInvalidUserFilter), errors.Is(err, domain.ErrInvalidCursor):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrCalendarUnavailable):
+		http.Error(w, err.Error(), http.StatusBadGateway)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func parseLimitOffset(r *http.Request) (int, int) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return limit, offset
+}
+
+// parsePage reads limit and either cursor or offset. Clients page by passing
+// back the next_cursor of the previous response; offset is kept for older
+// clients.
+func parsePage(r *http.Request) (domain.Page, error) {
+	limit, offset := parseLimitOffset(r)
+	page := domain.Page{Limit: limit, Offset: offset}
+
+	if value := r.URL.Query().Get("cursor"); value != "" {
+		cursor, err := domain.DecodeCursor(value)
+		if err != nil {
+			return domain.Page{}, err
+		}
+		page.After = cursor
+		page.Offset = 0
+	}
+	return page, nil
+}
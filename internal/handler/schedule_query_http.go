@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"ministry-scheduler/internal/usecase"
+)
+
+type ScheduleQueryHandler struct {
+	usecase *usecase.ScheduleQueryUsecase
+}
+
+func NewScheduleQueryHandler(usecase *usecase.ScheduleQueryUsecase) *ScheduleQueryHandler {
+	return &ScheduleQueryHandler{usecase: usecase}
+}
+
+func (h *ScheduleQueryHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/users/{id}/schedule-query", h.handleQuery)
+}
+
+func (h *ScheduleQueryHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	text := r.URL.Query().Get("q")
+	if text == "" {
+		http.Error(w, "Query text required", http.StatusBadRequest)
+		return
+	}
+
+	query, err := h.usecase.Query(ctx, id, text)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, query)
+}
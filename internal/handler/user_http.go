@@ -3,7 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -17,6 +17,8 @@ const (
 	requestTimeout = 10 * time.Second
 	defaultLimit   = 10
 	maxLimit       = 100
+
+	filterDateLayout = "2006-01-02"
 )
 
 type UserHandler struct {
@@ -75,26 +77,52 @@ func (h *UserHandler) handleUserByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserHandler) listUsers(ctx context.Context, w http.ResponseWriter, r *http.Request) {
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
-
-	if limit <= 0 {
-		limit = defaultLimit
+	page, err := parsePage(r)
+	if err != nil {
+		handleError(w, err)
+		return
 	}
-	if limit > maxLimit {
-		limit = maxLimit
+	filter, err := parseUserFilter(r)
+	if err != nil {
+		handleError(w, err)
+		return
 	}
 
-	users, err := h.usecase.ListUsers(ctx, limit, offset)
+	sort := domain.UserSort(r.URL.Query().Get("sort"))
+	users, err := h.usecase.ListUsers(ctx, filter, sort, page)
 	if err != nil {
-		h.handleError(w, err)
+		handleError(w, err)
 		return
 	}
 
-	h.writeJSONResponse(w, http.StatusOK, map[string]any{
-		"users": users,
-		"count": len(users),
-	})
+	writeJSONResponse(w, http.StatusOK, users)
+}
+
+// parseUserFilter reads the q, name, created_from and created_to
+// (YYYY-MM-DD, created_to exclusive) query parameters.
+func parseUserFilter(r *http.Request) (domain.UserFilter, error) {
+	query := r.URL.Query()
+	filter := domain.UserFilter{
+		Query: strings.TrimSpace(query.Get("q")),
+		Name:  strings.TrimSpace(query.Get("name")),
+	}
+
+	for param, bound := range map[string]*time.Time{
+		"created_from": &filter.CreatedFrom,
+		"created_to":   &filter.CreatedTo,
+	} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		date, err := time.ParseInLocation(filterDateLayout, value, time.Local)
+		if err != nil {
+			return domain.UserFilter{}, fmt.Errorf("%w: %s must be YYYY-MM-DD", domain.ErrInvalidUserFilter, param)
+		}
+		*bound = date
+	}
+
+	return filter, nil
 }
 
 func (h *UserHandler) createUser(ctx context.Context, w http.ResponseWriter, r *http.Request) {
@@ -106,21 +134,21 @@ func (h *UserHandler) createUser(ctx context.Context, w http.ResponseWriter, r *
 
 	user, err := h.usecase.CreateUser(ctx, &req)
 	if err != nil {
-		h.handleError(w, err)
+		handleError(w, err)
 		return
 	}
 
-	h.writeJSONResponse(w, http.StatusCreated, user)
+	writeJSONResponse(w, http.StatusCreated, user)
 }
 
 func (h *UserHandler) getUser(ctx context.Context, w http.ResponseWriter, id int64) {
 	user, err := h.usecase.GetUser(ctx, id)
 	if err != nil {
-		h.handleError(w, err)
+		handleError(w, err)
 		return
 	}
 
-	h.writeJSONResponse(w, http.StatusOK, user)
+	writeJSONResponse(w, http.StatusOK, user)
 }
 
 func (h *UserHandler) updateUser(ctx context.Context, w http.ResponseWriter, r *http.Request, id int64) {
@@ -132,41 +160,19 @@ func (h *UserHandler) updateUser(ctx context.Context, w http.ResponseWriter, r *
 
 	user, err := h.usecase.UpdateUser(ctx, id, &req)
 	if err != nil {
-		h.handleError(w, err)
+		handleError(w, err)
 		return
 	}
 
-	h.writeJSONResponse(w, http.StatusOK, user)
+	writeJSONResponse(w, http.StatusOK, user)
 }
 
 func (h *UserHandler) deleteUser(ctx context.Context, w http.ResponseWriter, id int64) {
 	err := h.usecase.DeleteUser(ctx, id)
 	if err != nil {
-		h.handleError(w, err)
+		handleError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
-
-// Helper methods for response handling.
-func (h *UserHandler) writeJSONResponse(w http.ResponseWriter, status int, data any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if encodeErr := json.NewEncoder(w).Encode(data); encodeErr != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-	}
-}
-
-func (h *UserHandler) handleError(w http.ResponseWriter, err error) {
-	switch {
-	case errors.Is(err, domain.ErrUserNotFound):
-		http.Error(w, err.Error(), http.StatusNotFound)
-	case errors.Is(err, domain.ErrUserExists):
-		http.Error(w, err.Error(), http.StatusConflict)
-	case errors.Is(err, domain.ErrEmptyName), errors.Is(err, domain.ErrInvalidEmail):
-		http.Error(w, err.Error(), http.StatusBadRequest)
-	default:
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-}
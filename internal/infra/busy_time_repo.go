@@ -0,0 +1,182 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+const busySourceColumns = `id, user_id, kind, url, last_synced_at, last_error, created_at`
+
+type SQLBusyTimeRepository struct {
+	db *sql.DB
+}
+
+func NewSQLBusyTimeRepository(db *sql.DB) *SQLBusyTimeRepository {
+	return &SQLBusyTimeRepository{db: db}
+}
+
+func (r *SQLBusyTimeRepository) CreateSource(
+	ctx context.Context,
+	source *domain.BusySource,
+) (*domain.BusySource, error) {
+	query := `INSERT INTO busy_sources (user_id, kind, url, last_synced_at, last_error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		source.UserID, source.Kind, source.URL, source.LastSyncedAt, source.LastError, source.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	source.ID = id
+	return source, nil
+}
+
+func (r *SQLBusyTimeRepository) GetSource(ctx context.Context, id int64) (*domain.BusySource, error) {
+	query := `SELECT ` + busySourceColumns + ` FROM busy_sources WHERE id = ?`
+	source, err := scanBusySource(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrBusySourceNotFound
+	}
+	return source, err
+}
+
+func (r *SQLBusyTimeRepository) ListSources(ctx context.Context, userID int64) ([]*domain.BusySource, error) {
+	query := `SELECT ` + busySourceColumns + ` FROM busy_sources WHERE user_id = ? ORDER BY id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBusySources(rows)
+}
+
+func (r *SQLBusyTimeRepository) ListURLSources(ctx context.Context) ([]*domain.BusySource, error) {
+	query := `SELECT ` + busySourceColumns + ` FROM busy_sources WHERE kind = ? ORDER BY id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, domain.BusySourceURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanBusySources(rows)
+}
+
+func (r *SQLBusyTimeRepository) UpdateSourceSync(ctx context.Context, source *domain.BusySource) error {
+	query := `UPDATE busy_sources SET last_synced_at = ?, last_error = ? WHERE id = ?`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, source.LastSyncedAt, source.LastError, source.ID)
+	if err != nil {
+		return err
+	}
+
+	return requireRowAffected(result, domain.ErrBusySourceNotFound)
+}
+
+func (r *SQLBusyTimeRepository) DeleteSource(ctx context.Context, id int64) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM busy_sources WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRowAffected(result, domain.ErrBusySourceNotFound)
+}
+
+func (r *SQLBusyTimeRepository) ReplaceBlocks(ctx context.Context, sourceID int64, blocks []domain.BusyBlock) error {
+	db := conn(ctx, r.db)
+	if _, err := db.ExecContext(ctx, `DELETE FROM busy_blocks WHERE source_id = ?`, sourceID); err != nil {
+		return err
+	}
+
+	query := `INSERT INTO busy_blocks (source_id, user_id, starts_at, ends_at) VALUES (?, ?, ?, ?)`
+	for _, block := range blocks {
+		if _, err := db.ExecContext(ctx, query, sourceID, block.UserID, block.Start.UTC(), block.End.UTC()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLBusyTimeRepository) ListBlocks(
+	ctx context.Context,
+	userID int64,
+	from, to time.Time,
+) ([]domain.BusyBlock, error) {
+	query := `SELECT source_id, user_id, starts_at, ends_at FROM busy_blocks
+		WHERE user_id = ? AND starts_at < ? AND ends_at > ? ORDER BY starts_at, id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID, to.UTC(), from.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var blocks []domain.BusyBlock
+	for rows.Next() {
+		var block domain.BusyBlock
+		if scanErr := rows.Scan(&block.SourceID, &block.UserID, &block.Start, &block.End); scanErr != nil {
+			return nil, scanErr
+		}
+		blocks = append(blocks, block)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, rowsErr
+	}
+
+	return blocks, nil
+}
+
+func scanBusySources(rows *sql.Rows) ([]*domain.BusySource, error) {
+	defer rows.Close()
+
+	var sources []*domain.BusySource
+	for rows.Next() {
+		source, err := scanBusySource(rows)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, rowsErr
+	}
+
+	return sources, nil
+}
+
+func scanBusySource(row rowScanner) (*domain.BusySource, error) {
+	var (
+		source       domain.BusySource
+		lastSyncedAt sql.NullTime
+	)
+	err := row.Scan(&source.ID, &source.UserID, &source.Kind, &source.URL, &lastSyncedAt, &source.LastError,
+		&source.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastSyncedAt.Valid {
+		source.LastSyncedAt = &lastSyncedAt.Time
+	}
+	return &source, nil
+}
+
+// requireRowAffected returns notFound when an UPDATE or DELETE matched no rows.
+func requireRowAffected(result sql.Result, notFound error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return notFound
+	}
+	return nil
+}
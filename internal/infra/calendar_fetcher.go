@@ -0,0 +1,139 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+const (
+	calendarFetchTimeout = 30 * time.Second
+	calendarDialTimeout  = 10 * time.Second
+	maxCalendarRedirects = 5
+)
+
+var ErrCalendarAddressBlocked = errors.New("calendar host is not a public address")
+
+// HTTPCalendarFetcher downloads ICS subscriptions over HTTP(S). Members choose
+// the URLs, so it only connects to public addresses: the check runs on every
+// dialled address, after DNS resolution and on each redirect, so a hostname
+// pointing at the server's own network is refused too.
+type HTTPCalendarFetcher struct {
+	httpClient *http.Client
+}
+
+func NewHTTPCalendarFetcher() *HTTPCalendarFetcher {
+	dialer := &net.Dialer{Timeout: calendarDialTimeout, Control: rejectNonPublicAddress}
+	return &HTTPCalendarFetcher{httpClient: &http.Client{
+		Timeout: calendarFetchTimeout,
+		// No proxy: the address check must see the calendar host itself.
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: calendarDialTimeout,
+			ForceAttemptHTTP2:   true,
+		},
+		CheckRedirect: checkCalendarRedirect,
+	}}
+}
+
+func (f *HTTPCalendarFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calendar fetch returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, domain.MaxCalendarSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > domain.MaxCalendarSize {
+		return nil, fmt.Errorf("%w: larger than %d bytes", domain.ErrInvalidCalendar, domain.MaxCalendarSize)
+	}
+	return data, nil
+}
+
+// checkCalendarRedirect refuses redirects to other schemes and to hosts that
+// resolve to non-public addresses.
+func checkCalendarRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxCalendarRedirects {
+		return errors.New("too many redirects")
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(req.Context(), "ip", req.URL.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if !isPublicAddress(addr) {
+			return ErrCalendarAddressBlocked
+		}
+	}
+	return nil
+}
+
+// rejectNonPublicAddress is a net.Dialer Control function; address is the
+// resolved IP and port about to be connected to.
+func rejectNonPublicAddress(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddress(addrPort.Addr()) {
+		return ErrCalendarAddressBlocked
+	}
+	return nil
+}
+
+// isPublicAddress rejects loopback, private (RFC 1918, fc00::/7), link-local
+// (including 169.254.169.254 metadata endpoints), multicast and the other
+// special-purpose ranges.
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range specialPurposePrefixes() {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// specialPurposePrefixes lists the non-public ranges that IsGlobalUnicast and
+// IsPrivate don't cover.
+func specialPurposePrefixes() []netip.Prefix {
+	return []netip.Prefix{
+		netip.MustParsePrefix("0.0.0.0/8"),      // "This" network
+		netip.MustParsePrefix("100.64.0.0/10"),  // Carrier-grade NAT
+		netip.MustParsePrefix("192.0.0.0/24"),   // IETF protocol assignments
+		netip.MustParsePrefix("198.18.0.0/15"),  // Benchmarking
+		netip.MustParsePrefix("240.0.0.0/4"),    // Reserved
+		netip.MustParsePrefix("64:ff9b::/96"),   // NAT64, which can reach IPv4 private ranges
+		netip.MustParsePrefix("64:ff9b:1::/48"), // Local-use NAT64
+		netip.MustParsePrefix("2001:db8::/32"),  // Documentation
+		netip.MustParsePrefix("2002::/16"),      // 6to4, which embeds IPv4 addresses
+	}
+}
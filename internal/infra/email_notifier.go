@@ -0,0 +1,218 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	texttemplate "text/template"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+const (
+	DefaultSMTPPort = "587"
+
+	smtpTimeout              = 30 * time.Second
+	smtpPermanentFailureCode = 500
+)
+
+//go:embed templates/notification.html.tmpl templates/notification.txt.tmpl
+var emailTemplates embed.FS
+
+// SMTPConfig describes the mail server used for email notifications. Username
+// and Password are optional; when set the server must support STARTTLS.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type emailData struct {
+	Name  string
+	Title string
+	Body  string
+}
+
+// EmailNotifier sends notifications to User.Email as multipart text and HTML
+// messages.
+type EmailNotifier struct {
+	config SMTPConfig
+	from   *mail.Address
+	html   *htmltemplate.Template
+	text   *texttemplate.Template
+}
+
+func NewEmailNotifier(config SMTPConfig) (*EmailNotifier, error) {
+	from, err := mail.ParseAddress(config.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", config.From, err)
+	}
+	if config.Port == "" {
+		config.Port = DefaultSMTPPort
+	}
+
+	html, err := htmltemplate.ParseFS(emailTemplates, "templates/notification.html.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.ParseFS(emailTemplates, "templates/notification.txt.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailNotifier{config: config, from: from, html: html, text: text}, nil
+}
+
+func (n *EmailNotifier) Channel() domain.NotificationChannel {
+	return domain.NotificationChannelEmail
+}
+
+func (n *EmailNotifier) Send(ctx context.Context, user *domain.User, notification *domain.Notification) error {
+	to, err := mail.ParseAddress(user.Email)
+	if err != nil {
+		return fmt.Errorf("%w: user %d has no valid email address", domain.ErrNotificationUndeliverable, user.ID)
+	}
+
+	message, err := n.buildMessage(to, user, notification)
+	if err != nil {
+		return err
+	}
+
+	err = n.deliver(ctx, to.Address, message)
+
+	// 5xx replies are permanent; retrying would fail the same way.
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) && smtpErr.Code >= smtpPermanentFailureCode {
+		return fmt.Errorf("%w: %w", domain.ErrNotificationUndeliverable, err)
+	}
+	return err
+}
+
+func (n *EmailNotifier) buildMessage(
+	to *mail.Address,
+	user *domain.User,
+	notification *domain.Notification,
+) ([]byte, error) {
+	data := emailData{Name: user.Name, Title: notification.Title, Body: notification.Body}
+
+	var buf bytes.Buffer
+	body := multipart.NewWriter(&buf)
+
+	headers := []string{
+		"From: " + n.from.String(),
+		"To: " + to.String(),
+		"Subject: " + mime.BEncoding.Encode("utf-8", notification.Title),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/alternative; boundary=" + body.Boundary(),
+	}
+	var message bytes.Buffer
+	for _, header := range headers {
+		message.WriteString(header + "\r\n")
+	}
+	message.WriteString("\r\n")
+
+	if err := writeEmailPart(body, "text/plain", func(w *quotedprintable.Writer) error {
+		return n.text.Execute(w, data)
+	}); err != nil {
+		return nil, err
+	}
+	if err := writeEmailPart(body, "text/html", func(w *quotedprintable.Writer) error {
+		return n.html.Execute(w, data)
+	}); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+
+	message.Write(buf.Bytes())
+	return message.Bytes(), nil
+}
+
+func writeEmailPart(body *multipart.Writer, contentType string, render func(w *quotedprintable.Writer) error) error {
+	part, err := body.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+
+	w := quotedprintable.NewWriter(part)
+	if renderErr := render(w); renderErr != nil {
+		return renderErr
+	}
+	return w.Close()
+}
+
+// deliver runs one SMTP transaction. net/smtp.SendMail has no context support,
+// so the connection is dialled here and bounded by ctx's deadline.
+func (n *EmailNotifier) deliver(ctx context.Context, to string, message []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, smtpTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.config.Host, n.config.Port))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if deadlineErr := conn.SetDeadline(deadline); deadlineErr != nil {
+			return deadlineErr
+		}
+	}
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if tlsErr := client.StartTLS(&tls.Config{ServerName: n.config.Host, MinVersion: tls.VersionTLS12}); tlsErr != nil {
+			return tlsErr
+		}
+	}
+	if n.config.Username != "" {
+		auth := smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.Host)
+		if authErr := client.Auth(auth); authErr != nil {
+			return authErr
+		}
+	}
+
+	if mailErr := client.Mail(n.from.Address); mailErr != nil {
+		return mailErr
+	}
+	if rcptErr := client.Rcpt(to); rcptErr != nil {
+		return rcptErr
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, writeErr := w.Write(message); writeErr != nil {
+		return writeErr
+	}
+	if closeErr := w.Close(); closeErr != nil {
+		return closeErr
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,41 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"ministry-scheduler/internal/domain"
+)
+
+type SQLLineBindingCodeRepository struct {
+	db *sql.DB
+}
+
+func NewSQLLineBindingCodeRepository(db *sql.DB) *SQLLineBindingCodeRepository {
+	return &SQLLineBindingCodeRepository{db: db}
+}
+
+func (r *SQLLineBindingCodeRepository) Save(ctx context.Context, code *domain.LineBindingCode) error {
+	query := `INSERT INTO line_binding_codes (code, user_id, expires_at, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET
+			code = excluded.code, expires_at = excluded.expires_at, created_at = excluded.created_at`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, code.Code, code.UserID, code.ExpiresAt, code.CreatedAt)
+	return err
+}
+
+func (r *SQLLineBindingCodeRepository) Consume(ctx context.Context, code string) (*domain.LineBindingCode, error) {
+	query := `DELETE FROM line_binding_codes WHERE code = ? RETURNING code, user_id, expires_at, created_at`
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, code)
+
+	var bindingCode domain.LineBindingCode
+	err := row.Scan(&bindingCode.Code, &bindingCode.UserID, &bindingCode.ExpiresAt, &bindingCode.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidBindingCode
+		}
+		return nil, err
+	}
+
+	return &bindingCode, nil
+}
@@ -0,0 +1,93 @@
+package infra
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+const (
+	DefaultLineAPIBaseURL = "https://api.line.me"
+
+	lineReplyPath        = "/v2/bot/message/reply"
+	linePushPath         = "/v2/bot/message/push"
+	lineClientTimeout    = 10 * time.Second
+	maxLineErrorBodySize = 1024
+)
+
+type lineReplyRequest struct {
+	ReplyToken string               `json:"replyToken"`
+	Messages   []domain.LineMessage `json:"messages"`
+}
+
+type linePushRequest struct {
+	To       string               `json:"to"`
+	Messages []domain.LineMessage `json:"messages"`
+}
+
+// LineAPIError is returned when the Line API answers with a non-200 status.
+type LineAPIError struct {
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *LineAPIError) Error() string {
+	return fmt.Sprintf("line api %s returned status %d: %s", e.Path, e.StatusCode, e.Message)
+}
+
+type LineHTTPClient struct {
+	baseURL     string
+	accessToken string
+	httpClient  *http.Client
+}
+
+func NewLineHTTPClient(baseURL, accessToken string) *LineHTTPClient {
+	return &LineHTTPClient{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		accessToken: accessToken,
+		httpClient:  &http.Client{Timeout: lineClientTimeout},
+	}
+}
+
+func (c *LineHTTPClient) ReplyMessage(ctx context.Context, replyToken string, messages []domain.LineMessage) error {
+	return c.post(ctx, lineReplyPath, lineReplyRequest{ReplyToken: replyToken, Messages: messages})
+}
+
+func (c *LineHTTPClient) PushMessage(ctx context.Context, to string, messages []domain.LineMessage) error {
+	return c.post(ctx, linePushPath, linePushRequest{To: to, Messages: messages})
+}
+
+func (c *LineHTTPClient) post(ctx context.Context, path string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxLineErrorBodySize))
+		return &LineAPIError{Path: path, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+
+	return nil
+}
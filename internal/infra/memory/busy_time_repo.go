@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+type BusyTimeRepository struct {
+	store *Store
+}
+
+func NewBusyTimeRepository(store *Store) *BusyTimeRepository {
+	return &BusyTimeRepository{store: store}
+}
+
+func (r *BusyTimeRepository) CreateSource(
+	ctx context.Context,
+	source *domain.BusySource,
+) (*domain.BusySource, error) {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	if err := d.requireUser(source.UserID); err != nil {
+		return nil, err
+	}
+
+	d.lastBusySourceID++
+	source.ID = d.lastBusySourceID
+	stored := *source
+	stored.LastSyncedAt = copyTime(source.LastSyncedAt)
+	stored.CreatedAt = source.CreatedAt.UTC()
+	d.busySources[source.ID] = stored
+	return source, nil
+}
+
+func (r *BusyTimeRepository) GetSource(ctx context.Context, id int64) (*domain.BusySource, error) {
+	defer r.store.lock(ctx)()
+
+	source, ok := r.store.data.busySources[id]
+	if !ok {
+		return nil, domain.ErrBusySourceNotFound
+	}
+	return copyBusySource(source), nil
+}
+
+func (r *BusyTimeRepository) ListSources(ctx context.Context, userID int64) ([]*domain.BusySource, error) {
+	defer r.store.lock(ctx)()
+
+	return r.store.data.busySourcesWhere(func(source domain.BusySource) bool { return source.UserID == userID }), nil
+}
+
+func (r *BusyTimeRepository) ListURLSources(ctx context.Context) ([]*domain.BusySource, error) {
+	defer r.store.lock(ctx)()
+
+	return r.store.data.busySourcesWhere(func(source domain.BusySource) bool {
+		return source.Kind == domain.BusySourceURL
+	}), nil
+}
+
+func (r *BusyTimeRepository) UpdateSourceSync(ctx context.Context, source *domain.BusySource) error {
+	defer r.store.lock(ctx)()
+
+	stored, ok := r.store.data.busySources[source.ID]
+	if !ok {
+		return domain.ErrBusySourceNotFound
+	}
+
+	stored.LastSyncedAt = copyTime(source.LastSyncedAt)
+	stored.LastError = source.LastError
+	r.store.data.busySources[source.ID] = stored
+	return nil
+}
+
+func (r *BusyTimeRepository) DeleteSource(ctx context.Context, id int64) error {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	if _, ok := d.busySources[id]; !ok {
+		return domain.ErrBusySourceNotFound
+	}
+
+	delete(d.busySources, id)
+	d.busyBlocks = slices.DeleteFunc(d.busyBlocks, func(row busyBlockRow) bool { return row.block.SourceID == id })
+	return nil
+}
+
+func (r *BusyTimeRepository) ReplaceBlocks(ctx context.Context, sourceID int64, blocks []domain.BusyBlock) error {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	if _, ok := d.busySources[sourceID]; !ok {
+		return domain.ErrBusySourceNotFound
+	}
+
+	d.busyBlocks = slices.DeleteFunc(d.busyBlocks, func(row busyBlockRow) bool {
+		return row.block.SourceID == sourceID
+	})
+	for _, block := range blocks {
+		d.lastBusyBlockID++
+		block.SourceID = sourceID
+		block.Start = block.Start.UTC()
+		block.End = block.End.UTC()
+		d.busyBlocks = append(d.busyBlocks, busyBlockRow{id: d.lastBusyBlockID, block: block})
+	}
+	return nil
+}
+
+func (r *BusyTimeRepository) ListBlocks(
+	ctx context.Context,
+	userID int64,
+	from, to time.Time,
+) ([]domain.BusyBlock, error) {
+	defer r.store.lock(ctx)()
+
+	var rows []busyBlockRow
+	for _, row := range r.store.data.busyBlocks {
+		if row.block.UserID == userID && row.block.Start.Before(to) && row.block.End.After(from) {
+			rows = append(rows, row)
+		}
+	}
+	slices.SortFunc(rows, func(a, b busyBlockRow) int {
+		return cmp.Or(a.block.Start.Compare(b.block.Start), cmp.Compare(a.id, b.id))
+	})
+
+	var blocks []domain.BusyBlock
+	for _, row := range rows {
+		blocks = append(blocks, row.block)
+	}
+	return blocks, nil
+}
+
+// busySourcesWhere returns copies of the sources that match, by ID.
+func (d *data) busySourcesWhere(match func(domain.BusySource) bool) []*domain.BusySource {
+	var sources []*domain.BusySource
+	for _, source := range d.busySources {
+		if match(source) {
+			sources = append(sources, copyBusySource(source))
+		}
+	}
+	slices.SortFunc(sources, func(a, b *domain.BusySource) int { return cmp.Compare(a.ID, b.ID) })
+	return sources
+}
+
+func copyBusySource(source domain.BusySource) *domain.BusySource {
+	source.LastSyncedAt = copyTime(source.LastSyncedAt)
+	return &source
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"maps"
+
+	"ministry-scheduler/internal/domain"
+)
+
+type LineBindingCodeRepository struct {
+	store *Store
+}
+
+func NewLineBindingCodeRepository(store *Store) *LineBindingCodeRepository {
+	return &LineBindingCodeRepository{store: store}
+}
+
+func (r *LineBindingCodeRepository) Save(ctx context.Context, code *domain.LineBindingCode) error {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	if err := d.requireUser(code.UserID); err != nil {
+		return err
+	}
+
+	maps.DeleteFunc(d.bindingCodes, func(_ string, stored domain.LineBindingCode) bool {
+		return stored.UserID == code.UserID
+	})
+	stored := *code
+	stored.ExpiresAt = code.ExpiresAt.UTC()
+	stored.CreatedAt = code.CreatedAt.UTC()
+	d.bindingCodes[code.Code] = stored
+	return nil
+}
+
+func (r *LineBindingCodeRepository) Consume(ctx context.Context, code string) (*domain.LineBindingCode, error) {
+	defer r.store.lock(ctx)()
+
+	stored, ok := r.store.data.bindingCodes[code]
+	if !ok {
+		return nil, domain.ErrInvalidBindingCode
+	}
+
+	delete(r.store.data.bindingCodes, code)
+	return &stored, nil
+}
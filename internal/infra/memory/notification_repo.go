@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+type NotificationRepository struct {
+	store *Store
+}
+
+func NewNotificationRepository(store *Store) *NotificationRepository {
+	return &NotificationRepository{store: store}
+}
+
+func (r *NotificationRepository) Enqueue(ctx context.Context, notifications []*domain.Notification) error {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	for _, n := range notifications {
+		if err := d.requireUser(n.UserID); err != nil {
+			return err
+		}
+	}
+
+	for _, n := range notifications {
+		d.lastNotificationID++
+		n.ID = d.lastNotificationID
+		d.notifications[n.ID] = notificationRow{notification: storedNotification(n)}
+	}
+	return nil
+}
+
+func (r *NotificationRepository) GetByID(ctx context.Context, id int64) (*domain.Notification, error) {
+	defer r.store.lock(ctx)()
+
+	row, ok := r.store.data.notifications[id]
+	if !ok {
+		return nil, domain.ErrNotificationNotFound
+	}
+	return copyNotification(row), nil
+}
+
+// ClaimDue leases the due rows under the store's lock, so two workers never
+// claim the same row.
+func (r *NotificationRepository) ClaimDue(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+	lockedUntil time.Time,
+) ([]*domain.Notification, error) {
+	defer r.store.lock(ctx)()
+
+	var due []notificationRow
+	for _, row := range r.store.data.notifications {
+		n := row.notification
+		if n.Status == domain.NotificationStatusPending && !n.NextAttemptAt.After(now) &&
+			(row.lockedUntil.IsZero() || !row.lockedUntil.After(now)) {
+			due = append(due, row)
+		}
+	}
+	slices.SortFunc(due, func(a, b notificationRow) int {
+		return cmp.Or(a.notification.NextAttemptAt.Compare(b.notification.NextAttemptAt),
+			cmp.Compare(a.notification.ID, b.notification.ID))
+	})
+
+	var notifications []*domain.Notification
+	for _, row := range page(due, limit, 0) {
+		row.lockedUntil = lockedUntil.UTC()
+		r.store.data.notifications[row.notification.ID] = row
+		notifications = append(notifications, copyNotification(row))
+	}
+	return notifications, nil
+}
+
+func (r *NotificationRepository) UpdateDelivery(ctx context.Context, n *domain.Notification) error {
+	defer r.store.lock(ctx)()
+
+	row, ok := r.store.data.notifications[n.ID]
+	if !ok {
+		return domain.ErrNotificationNotFound
+	}
+	var lease time.Time
+	if n.LockedUntil != nil {
+		lease = n.LockedUntil.UTC()
+	}
+	if !row.lockedUntil.Equal(lease) {
+		return domain.ErrNotificationLeaseLost
+	}
+
+	updated := row.notification
+	updated.Status = n.Status
+	updated.Attempts = n.Attempts
+	updated.LastError = n.LastError
+	updated.NextAttemptAt = n.NextAttemptAt.UTC()
+	updated.SentAt = copyTime(n.SentAt)
+	r.store.data.notifications[n.ID] = notificationRow{notification: updated}
+	n.LockedUntil = nil
+	return nil
+}
+
+func (r *NotificationRepository) ListByStatus(
+	ctx context.Context,
+	status domain.NotificationStatus,
+	p domain.Page,
+) ([]*domain.Notification, error) {
+	defer r.store.lock(ctx)()
+
+	var notifications []*domain.Notification
+	for _, row := range r.store.data.notifications {
+		if row.notification.Status == status && (p.After == nil || row.notification.ID < p.After.ID) {
+			notifications = append(notifications, copyNotification(row))
+		}
+	}
+	slices.SortFunc(notifications, func(a, b *domain.Notification) int { return cmp.Compare(b.ID, a.ID) })
+
+	return page(notifications, p.Limit, p.Offset), nil
+}
+
+func (r *NotificationRepository) CountByStatus(ctx context.Context, status domain.NotificationStatus) (int, error) {
+	defer r.store.lock(ctx)()
+
+	count := 0
+	for _, row := range r.store.data.notifications {
+		if row.notification.Status == status {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func storedNotification(n *domain.Notification) domain.Notification {
+	stored := *n
+	stored.NextAttemptAt = n.NextAttemptAt.UTC()
+	stored.CreatedAt = n.CreatedAt.UTC()
+	stored.SentAt = copyTime(n.SentAt)
+	return stored
+}
+
+func copyNotification(row notificationRow) *domain.Notification {
+	n := row.notification
+	n.SentAt = copyTime(n.SentAt)
+	if !row.lockedUntil.IsZero() {
+		n.LockedUntil = &row.lockedUntil
+	}
+	return &n
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+type InAppNotificationRepository struct {
+	store *Store
+}
+
+func NewInAppNotificationRepository(store *Store) *InAppNotificationRepository {
+	return &InAppNotificationRepository{store: store}
+}
+
+func (r *InAppNotificationRepository) Create(ctx context.Context, n *domain.InAppNotification) error {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	if err := d.requireUser(n.UserID); err != nil {
+		return err
+	}
+
+	d.lastInAppID++
+	n.ID = d.lastInAppID
+	stored := *n
+	stored.CreatedAt = n.CreatedAt.UTC()
+	d.inApp[n.ID] = stored
+	return nil
+}
+
+func (r *InAppNotificationRepository) ListByUser(
+	ctx context.Context,
+	userID int64,
+	p domain.Page,
+) ([]*domain.InAppNotification, error) {
+	defer r.store.lock(ctx)()
+
+	var notifications []*domain.InAppNotification
+	for _, n := range r.store.data.inApp {
+		if n.UserID == userID {
+			notifications = append(notifications, &n)
+		}
+	}
+	newestFirst := func(a, b *domain.InAppNotification) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	}
+	slices.SortFunc(notifications, newestFirst)
+	if p.After != nil {
+		after := &domain.InAppNotification{ID: p.After.ID, CreatedAt: p.After.CreatedAt}
+		notifications = slices.DeleteFunc(notifications, func(n *domain.InAppNotification) bool {
+			return newestFirst(n, after) <= 0
+		})
+	}
+
+	return page(notifications, p.Limit, p.Offset), nil
+}
+
+func (r *InAppNotificationRepository) CountByUser(ctx context.Context, userID int64) (int, error) {
+	defer r.store.lock(ctx)()
+
+	count := 0
+	for _, n := range r.store.data.inApp {
+		if n.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
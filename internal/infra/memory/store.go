@@ -0,0 +1,136 @@
+// Package memory implements the domain repositories in process memory, for
+// demos and tests. Nothing survives a restart.
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// Store holds the data shared by the repositories and the Transactor built on
+// it. It is safe for concurrent use.
+type Store struct {
+	mu   sync.Mutex
+	data *data
+}
+
+func NewStore() *Store {
+	return &Store{data: &data{
+		users:         map[int64]domain.User{},
+		lineAccounts:  map[int64]string{},
+		preferences:   map[int64]domain.NotificationPreferences{},
+		bindingCodes:  map[string]domain.LineBindingCode{},
+		notifications: map[int64]notificationRow{},
+		inApp:         map[int64]domain.InAppNotification{},
+		busySources:   map[int64]domain.BusySource{},
+	}}
+}
+
+// data mirrors the SQL tables. Rows are replaced, never changed in place, so
+// a shallow clone is a consistent snapshot.
+type data struct {
+	users         map[int64]domain.User
+	lineAccounts  map[int64]string
+	preferences   map[int64]domain.NotificationPreferences
+	bindingCodes  map[string]domain.LineBindingCode
+	notifications map[int64]notificationRow
+	inApp         map[int64]domain.InAppNotification
+	busySources   map[int64]domain.BusySource
+	busyBlocks    []busyBlockRow
+
+	lastUserID         int64
+	lastNotificationID int64
+	lastInAppID        int64
+	lastBusySourceID   int64
+	lastBusyBlockID    int64
+}
+
+type notificationRow struct {
+	notification domain.Notification
+	// lockedUntil is zero when the row is not leased.
+	lockedUntil time.Time
+}
+
+type busyBlockRow struct {
+	id    int64
+	block domain.BusyBlock
+}
+
+func (d *data) clone() *data {
+	clone := *d
+	clone.users = maps.Clone(d.users)
+	clone.lineAccounts = maps.Clone(d.lineAccounts)
+	clone.preferences = maps.Clone(d.preferences)
+	clone.bindingCodes = maps.Clone(d.bindingCodes)
+	clone.notifications = maps.Clone(d.notifications)
+	clone.inApp = maps.Clone(d.inApp)
+	clone.busySources = maps.Clone(d.busySources)
+	clone.busyBlocks = slices.Clone(d.busyBlocks)
+	return &clone
+}
+
+// requireUser stands in for the foreign keys on users(id).
+func (d *data) requireUser(id int64) error {
+	if _, ok := d.users[id]; !ok {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+type txKey struct{}
+
+// lock takes the store's lock and returns the function that releases it.
+// Inside WithinTx the transaction already holds the lock.
+func (s *Store) lock(ctx context.Context) func() {
+	if tx, _ := ctx.Value(txKey{}).(*Store); tx == s {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+type Transactor struct {
+	store *Store
+}
+
+func NewTransactor(store *Store) *Transactor {
+	return &Transactor{store: store}
+}
+
+// WithinTx runs fn holding the store's lock, so transactions never
+// interleave, and puts the data back as it was if fn fails. Nested calls join
+// the outer transaction. fn must pass its ctx to the repositories; calls with
+// any other context wait for the transaction to end.
+func (t *Transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, _ := ctx.Value(txKey{}).(*Store); tx == t.store {
+		return fn(ctx)
+	}
+
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	snapshot := t.store.data.clone()
+	if fnErr := fn(context.WithValue(ctx, txKey{}, t.store)); fnErr != nil {
+		t.store.data = snapshot
+		return fnErr
+	}
+
+	return nil
+}
+
+// page returns the rows of items a LIMIT and OFFSET would select.
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,272 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"ministry-scheduler/internal/domain"
+)
+
+type UserRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{store: store}
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	defer r.store.lock(ctx)()
+
+	return r.store.data.user(id)
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	defer r.store.lock(ctx)()
+
+	for id, user := range r.store.data.users {
+		if user.Email == email {
+			return r.store.data.user(id)
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *UserRepository) GetByLineUserID(ctx context.Context, lineUserID string) (*domain.User, error) {
+	defer r.store.lock(ctx)()
+
+	for id, boundID := range r.store.data.lineAccounts {
+		if boundID == lineUserID {
+			return r.store.data.user(id)
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	if d.emailTaken(user.Email, 0) {
+		return nil, domain.ErrUserExists
+	}
+
+	d.lastUserID++
+	user.ID = d.lastUserID
+	d.users[user.ID] = domain.User{
+		ID:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt.UTC(),
+		UpdatedAt: user.UpdatedAt.UTC(),
+	}
+	return user, nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) (*domain.User, error) {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	stored, ok := d.users[user.ID]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	if d.emailTaken(user.Email, user.ID) {
+		return nil, domain.ErrUserExists
+	}
+
+	stored.Name = user.Name
+	stored.Email = user.Email
+	stored.UpdatedAt = user.UpdatedAt.UTC()
+	d.users[user.ID] = stored
+	return user, nil
+}
+
+// Delete removes the user and, like the ON DELETE CASCADE foreign keys, the
+// rows that belong to them.
+func (r *UserRepository) Delete(ctx context.Context, id int64) error {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	if err := d.requireUser(id); err != nil {
+		return err
+	}
+
+	delete(d.users, id)
+	delete(d.lineAccounts, id)
+	delete(d.preferences, id)
+	maps.DeleteFunc(d.bindingCodes, func(_ string, code domain.LineBindingCode) bool { return code.UserID == id })
+	maps.DeleteFunc(d.notifications, func(_ int64, row notificationRow) bool {
+		return row.notification.UserID == id
+	})
+	maps.DeleteFunc(d.inApp, func(_ int64, n domain.InAppNotification) bool { return n.UserID == id })
+	maps.DeleteFunc(d.busySources, func(_ int64, source domain.BusySource) bool { return source.UserID == id })
+	d.busyBlocks = slices.DeleteFunc(d.busyBlocks, func(row busyBlockRow) bool { return row.block.UserID == id })
+	return nil
+}
+
+func (r *UserRepository) List(
+	ctx context.Context,
+	filter domain.UserFilter,
+	sort domain.UserSort,
+	p domain.Page,
+) ([]*domain.User, error) {
+	defer r.store.lock(ctx)()
+
+	users := r.store.data.matchingUsers(filter)
+	slices.SortFunc(users, func(a, b *domain.User) int { return compareUsers(sort, a, b) })
+	if p.After != nil {
+		after := &domain.User{ID: p.After.ID, Name: p.After.Key, CreatedAt: p.After.CreatedAt}
+		users = slices.DeleteFunc(users, func(user *domain.User) bool { return compareUsers(sort, user, after) <= 0 })
+	}
+
+	return page(users, p.Limit, p.Offset), nil
+}
+
+func (r *UserRepository) Count(ctx context.Context, filter domain.UserFilter) (int, error) {
+	defer r.store.lock(ctx)()
+
+	return len(r.store.data.matchingUsers(filter)), nil
+}
+
+func (r *UserRepository) BindLineAccount(ctx context.Context, userID int64, lineUserID string) error {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	if err := d.requireUser(userID); err != nil {
+		return err
+	}
+	for id, boundID := range d.lineAccounts {
+		if boundID == lineUserID && id != userID {
+			return domain.ErrLineAccountBound
+		}
+	}
+
+	d.lineAccounts[userID] = lineUserID
+	return nil
+}
+
+func (r *UserRepository) UnbindLineAccount(ctx context.Context, userID int64) error {
+	defer r.store.lock(ctx)()
+
+	delete(r.store.data.lineAccounts, userID)
+	return nil
+}
+
+func (r *UserRepository) UpdateNotificationPreferences(
+	ctx context.Context,
+	userID int64,
+	preferences domain.NotificationPreferences,
+) error {
+	defer r.store.lock(ctx)()
+
+	d := r.store.data
+	if err := d.requireUser(userID); err != nil {
+		return err
+	}
+
+	d.preferences[userID] = clonePreferences(preferences)
+	return nil
+}
+
+// user returns a copy of the user with their Line binding and notification
+// preferences, as selectUserQuery does.
+func (d *data) user(id int64) (*domain.User, error) {
+	user, ok := d.users[id]
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+
+	user.LineUserID = d.lineAccounts[id]
+	user.NotificationPreferences = clonePreferences(d.preferences[id])
+	return &user, nil
+}
+
+func (d *data) emailTaken(email string, exceptID int64) bool {
+	for id, user := range d.users {
+		if user.Email == email && id != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *data) matchingUsers(filter domain.UserFilter) []*domain.User {
+	var users []*domain.User
+	for id, user := range d.users {
+		if !matchesUserFilter(user, filter) {
+			continue
+		}
+		full, _ := d.user(id) // The user exists, so this cannot fail
+		users = append(users, full)
+	}
+	return users
+}
+
+func matchesUserFilter(user domain.User, filter domain.UserFilter) bool {
+	if filter.Query != "" && !containsFold(user.Name, filter.Query) && !containsFold(user.Email, filter.Query) {
+		return false
+	}
+	if filter.Name != "" && !containsFold(user.Name, filter.Name) {
+		return false
+	}
+	if !filter.CreatedFrom.IsZero() && user.CreatedAt.Before(filter.CreatedFrom) {
+		return false
+	}
+	if !filter.CreatedTo.IsZero() && !user.CreatedAt.Before(filter.CreatedTo) {
+		return false
+	}
+	return true
+}
+
+// containsFold folds ASCII letters only, like SQLite's LIKE, so "É" and "é"
+// stay distinct in every repository.
+func containsFold(s, substr string) bool {
+	return strings.Contains(asciiLower(s), asciiLower(substr))
+}
+
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+		return r
+	}, s)
+}
+
+// compareUsers orders a before b in sort order, with the ID breaking ties.
+// Names compare bytewise, as in the SQL repositories.
+func compareUsers(sort domain.UserSort, a, b *domain.User) int {
+	switch sort {
+	case domain.UserSortOldest:
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	case domain.UserSortName:
+		return cmp.Or(strings.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	case domain.UserSortNameDesc:
+		return cmp.Or(strings.Compare(b.Name, a.Name), cmp.Compare(b.ID, a.ID))
+	case domain.UserSortNewest:
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	default:
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	}
+}
+
+// clonePreferences copies the maps in preferences so callers can't change
+// the stored value.
+func clonePreferences(preferences domain.NotificationPreferences) domain.NotificationPreferences {
+	var clone domain.NotificationPreferences
+	if preferences.Channels != nil {
+		clone.Channels = make(map[domain.NotificationEvent]map[domain.NotificationChannel]bool, len(preferences.Channels))
+		for event, channels := range preferences.Channels {
+			clone.Channels[event] = maps.Clone(channels)
+		}
+	}
+	if preferences.QuietHours != nil {
+		quietHours := *preferences.QuietHours
+		clone.QuietHours = &quietHours
+	}
+	return clone
+}
@@ -0,0 +1,229 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var (
+	migrationNameRegex = regexp.MustCompile(`^(\d+)_(\w+)\.sql$`)
+
+	ErrDatabaseTooNew = errors.New("database schema is newer than this binary")
+)
+
+// Migration is one step of the schema history, embedded from
+// migrations/<version>_<name>.sql.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Dialect describes the SQL that differs between the databases a Migrator
+// runs on.
+type Dialect struct {
+	// Placeholder returns the bind parameter for the nth argument, from 1.
+	Placeholder func(n int) string
+	// TableExistsQuery counts the tables named by its single argument.
+	TableExistsQuery string
+	// LockQuery, if set, runs first in each migration transaction so
+	// concurrent migrators wait for each other.
+	LockQuery string
+}
+
+// SQLiteDialect needs no LockQuery: the DSN makes transactions take the
+// write lock when they begin.
+func SQLiteDialect() Dialect {
+	return Dialect{
+		Placeholder:      func(int) string { return "?" },
+		TableExistsQuery: `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`,
+	}
+}
+
+// Migrator applies the migrations in a directory of
+// <version>_<name>.sql files.
+type Migrator struct {
+	db      *sql.DB
+	files   fs.FS
+	dialect Dialect
+}
+
+func NewMigrator(db *sql.DB, files fs.FS, dialect Dialect) *Migrator {
+	return &Migrator{db: db, files: files, dialect: dialect}
+}
+
+// Migrations returns the embedded SQLite migrations in version order.
+func Migrations() ([]Migration, error) {
+	return loadMigrations(migrationFiles, "migrations")
+}
+
+// PendingMigrations reports the SQLite migrations Migrate would apply.
+func PendingMigrations(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	return newSQLiteMigrator(db).Pending(ctx)
+}
+
+// Migrate applies the pending SQLite migrations.
+func Migrate(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	return newSQLiteMigrator(db).Migrate(ctx)
+}
+
+func newSQLiteMigrator(db *sql.DB) *Migrator {
+	files, _ := fs.Sub(migrationFiles, "migrations") // The directory is embedded, so Sub cannot fail
+	return NewMigrator(db, files, SQLiteDialect())
+}
+
+// Migrations returns the migrations in version order.
+func (m *Migrator) Migrations() ([]Migration, error) {
+	return loadMigrations(m.files, ".")
+}
+
+func loadMigrations(files fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		match := migrationNameRegex.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		data, readErr := fs.ReadFile(files, path.Join(dir, entry.Name()))
+		if readErr != nil {
+			return nil, readErr
+		}
+		migrations = append(migrations, Migration{Version: version, Name: match[2], SQL: string(data)})
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// Pending returns the migrations not yet applied without changing the
+// database. It fails with ErrDatabaseTooNew if the database has a migration
+// this binary doesn't know.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+	if len(applied) > 0 && slices.Max(applied) > latest {
+		return nil, fmt.Errorf("%w: database is at version %d, binary knows up to %d",
+			ErrDatabaseTooNew, slices.Max(applied), latest)
+	}
+
+	return slices.DeleteFunc(migrations, func(migration Migration) bool {
+		return slices.Contains(applied, migration.Version)
+	}), nil
+}
+
+// Migrate applies the pending migrations in order, each in its own
+// transaction, and returns the ones it applied.
+func (m *Migrator) Migrate(ctx context.Context) ([]Migration, error) {
+	pending, err := m.Pending(ctx)
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+
+	createQuery := `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`
+	if _, err = m.db.ExecContext(ctx, createQuery); err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, migration := range pending {
+		if applyErr := m.apply(ctx, migration); applyErr != nil {
+			return applied, fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, applyErr)
+		}
+		applied = append(applied, migration)
+	}
+	return applied, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }() // No-op after Commit
+
+	if m.dialect.LockQuery != "" {
+		if _, err = tx.ExecContext(ctx, m.dialect.LockQuery); err != nil {
+			return err
+		}
+	}
+
+	// Another process may have applied it since Pending; the transaction
+	// holds the lock, so this check is reliable.
+	var exists int
+	query := `SELECT COUNT(*) FROM schema_migrations WHERE version = ` + m.dialect.Placeholder(1)
+	if err = tx.QueryRowContext(ctx, query, migration.Version).Scan(&exists); err != nil || exists > 0 {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, migration.SQL); err != nil {
+		return err
+	}
+	query = fmt.Sprintf(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (%s, %s, %s)`,
+		m.dialect.Placeholder(1), m.dialect.Placeholder(2), m.dialect.Placeholder(3))
+	if _, err = tx.ExecContext(ctx, query, migration.Version, migration.Name, time.Now().UTC()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) applied(ctx context.Context) ([]int, error) {
+	var tables int
+	err := m.db.QueryRowContext(ctx, m.dialect.TableExistsQuery, "schema_migrations").Scan(&tables)
+	if err != nil || tables == 0 {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var version int
+		if scanErr := rows.Scan(&version); scanErr != nil {
+			return nil, scanErr
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
@@ -0,0 +1,9 @@
+-- Statements use IF NOT EXISTS so databases created before versioned
+-- migrations, which already have these tables, adopt the history cleanly.
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	email TEXT UNIQUE NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
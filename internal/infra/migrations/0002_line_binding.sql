@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_line_accounts (
+	user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	line_user_id TEXT UNIQUE NOT NULL,
+	bound_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS line_binding_codes (
+	code TEXT PRIMARY KEY,
+	user_id INTEGER UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	expires_at DATETIME NOT NULL,
+	created_at DATETIME NOT NULL
+);
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS notification_outbox (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	event TEXT NOT NULL,
+	channel TEXT NOT NULL,
+	title TEXT NOT NULL,
+	body TEXT NOT NULL,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	next_attempt_at DATETIME NOT NULL,
+	locked_until DATETIME,
+	sent_at DATETIME,
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_outbox_due ON notification_outbox (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS in_app_notifications (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	event TEXT NOT NULL,
+	title TEXT NOT NULL,
+	body TEXT NOT NULL,
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_in_app_notifications_user ON in_app_notifications (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS user_notification_preferences (
+	user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	preferences TEXT NOT NULL,
+	updated_at DATETIME NOT NULL
+);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS busy_sources (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	kind TEXT NOT NULL,
+	url TEXT NOT NULL DEFAULT '',
+	last_synced_at DATETIME,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_busy_sources_user ON busy_sources (user_id);
+
+CREATE TABLE IF NOT EXISTS busy_blocks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	source_id INTEGER NOT NULL REFERENCES busy_sources(id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	starts_at DATETIME NOT NULL,
+	ends_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_busy_blocks_user_time ON busy_blocks (user_id, starts_at);
+CREATE INDEX IF NOT EXISTS idx_busy_blocks_source ON busy_blocks (source_id);
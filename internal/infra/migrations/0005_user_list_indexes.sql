@@ -0,0 +1,4 @@
+-- Keyset paging orders by (created_at, id) or (name, id).
+DROP INDEX IF EXISTS idx_users_created_at;
+CREATE INDEX IF NOT EXISTS idx_users_created_at_id ON users (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_users_name_id ON users (name, id);
@@ -0,0 +1,16 @@
+-- Users created before timestamps were stored in UTC kept the server's
+-- offset ("2024-01-15 17:30:00+08:00"). The created_at filters and keyset
+-- cursors compare the text, so rewrite those values in UTC, in the same
+-- format the driver writes: fractional seconds are kept as they were, and
+-- offsets are whole minutes, so only the date and time part changes.
+UPDATE users
+SET created_at = strftime('%Y-%m-%d %H:%M:%S', created_at)
+	|| substr(created_at, 20, length(created_at) - 25) || '+00:00'
+WHERE created_at GLOB '????-??-?? ??:??:??*[+-][0-9][0-9]:[0-9][0-9]'
+	AND created_at NOT LIKE '%+00:00';
+
+UPDATE users
+SET updated_at = strftime('%Y-%m-%d %H:%M:%S', updated_at)
+	|| substr(updated_at, 20, length(updated_at) - 25) || '+00:00'
+WHERE updated_at GLOB '????-??-?? ??:??:??*[+-][0-9][0-9]:[0-9][0-9]'
+	AND updated_at NOT LIKE '%+00:00';
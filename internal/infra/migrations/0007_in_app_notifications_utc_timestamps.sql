@@ -0,0 +1,8 @@
+-- The inbox pages by (created_at, id) and compares the text, so rewrite
+-- creation times stored with the server's offset in UTC, as 0006 does for
+-- users.
+UPDATE in_app_notifications
+SET created_at = strftime('%Y-%m-%d %H:%M:%S', created_at)
+	|| substr(created_at, 20, length(created_at) - 25) || '+00:00'
+WHERE created_at GLOB '????-??-?? ??:??:??*[+-][0-9][0-9]:[0-9][0-9]'
+	AND created_at NOT LIKE '%+00:00';
@@ -0,0 +1,264 @@
+package infra
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+const notificationColumns = `id, user_id, event, channel, title, body, status, attempts, last_error,
+	next_attempt_at, sent_at, created_at, locked_until`
+
+type SQLNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLNotificationRepository(db *sql.DB) *SQLNotificationRepository {
+	return &SQLNotificationRepository{db: db}
+}
+
+func (r *SQLNotificationRepository) Enqueue(ctx context.Context, notifications []*domain.Notification) error {
+	query := `INSERT INTO notification_outbox
+		(user_id, event, channel, title, body, status, attempts, last_error, next_attempt_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	for _, n := range notifications {
+		result, err := conn(ctx, r.db).ExecContext(ctx, query, n.UserID, n.Event, n.Channel, n.Title, n.Body,
+			n.Status, n.Attempts, n.LastError, n.NextAttemptAt.UTC(), n.CreatedAt.UTC())
+		if err != nil {
+			return err
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		n.ID = id
+	}
+
+	return nil
+}
+
+func (r *SQLNotificationRepository) GetByID(ctx context.Context, id int64) (*domain.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notification_outbox WHERE id = ?`
+	notification, err := scanNotification(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotificationNotFound
+	}
+	return notification, err
+}
+
+// ClaimDue marks the due rows as leased and returns them in a single
+// statement, so two workers never claim the same row.
+func (r *SQLNotificationRepository) ClaimDue(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+	lockedUntil time.Time,
+) ([]*domain.Notification, error) {
+	query := `UPDATE notification_outbox SET locked_until = ?
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE status = ? AND next_attempt_at <= ? AND (locked_until IS NULL OR locked_until <= ?)
+			ORDER BY next_attempt_at, id LIMIT ?
+		)
+		RETURNING ` + notificationColumns
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query,
+		lockedUntil.UTC(), domain.NotificationStatusPending, now.UTC(), now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := scanNotifications(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not follow the subquery order.
+	slices.SortFunc(notifications, func(a, b *domain.Notification) int {
+		return cmp.Or(a.NextAttemptAt.Compare(b.NextAttemptAt), cmp.Compare(a.ID, b.ID))
+	})
+	return notifications, nil
+}
+
+// UpdateDelivery only updates the row while it still holds the caller's lease:
+// the locked_until that ClaimDue returned, or none.
+func (r *SQLNotificationRepository) UpdateDelivery(ctx context.Context, n *domain.Notification) error {
+	query := `UPDATE notification_outbox
+		SET status = ?, attempts = ?, last_error = ?, next_attempt_at = ?, sent_at = ?, locked_until = NULL
+		WHERE id = ? AND locked_until IS ?`
+	var lockedUntil any
+	if n.LockedUntil != nil {
+		lockedUntil = n.LockedUntil.UTC()
+	}
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		n.Status, n.Attempts, n.LastError, n.NextAttemptAt.UTC(), n.SentAt, n.ID, lockedUntil)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return r.missingOrLeaseLost(ctx, n.ID)
+	}
+
+	n.LockedUntil = nil
+	return nil
+}
+
+// missingOrLeaseLost explains why UpdateDelivery matched no row.
+func (r *SQLNotificationRepository) missingOrLeaseLost(ctx context.Context, id int64) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM notification_outbox WHERE id = ?)`
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrNotificationLeaseLost
+	}
+	return domain.ErrNotificationNotFound
+}
+
+func (r *SQLNotificationRepository) ListByStatus(
+	ctx context.Context,
+	status domain.NotificationStatus,
+	page domain.Page,
+) ([]*domain.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notification_outbox WHERE status = ?`
+	args := []any{status}
+	if page.After != nil {
+		query += ` AND id < ?`
+		args = append(args, page.After.ID)
+	}
+
+	query += ` ORDER BY id DESC LIMIT ? OFFSET ?`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append(args, page.Limit, page.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanNotifications(rows)
+}
+
+func (r *SQLNotificationRepository) CountByStatus(ctx context.Context, status domain.NotificationStatus) (int, error) {
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notification_outbox WHERE status = ?`, status).Scan(&count)
+	return count, err
+}
+
+func scanNotifications(rows *sql.Rows) ([]*domain.Notification, error) {
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, rowsErr
+	}
+
+	return notifications, nil
+}
+
+func scanNotification(row rowScanner) (*domain.Notification, error) {
+	var (
+		n           domain.Notification
+		sentAt      sql.NullTime
+		lockedUntil sql.NullTime
+	)
+	err := row.Scan(&n.ID, &n.UserID, &n.Event, &n.Channel, &n.Title, &n.Body, &n.Status, &n.Attempts,
+		&n.LastError, &n.NextAttemptAt, &sentAt, &n.CreatedAt, &lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	if sentAt.Valid {
+		n.SentAt = &sentAt.Time
+	}
+	if lockedUntil.Valid {
+		n.LockedUntil = &lockedUntil.Time
+	}
+	return &n, nil
+}
+
+type SQLInAppNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewSQLInAppNotificationRepository(db *sql.DB) *SQLInAppNotificationRepository {
+	return &SQLInAppNotificationRepository{db: db}
+}
+
+func (r *SQLInAppNotificationRepository) Create(ctx context.Context, n *domain.InAppNotification) error {
+	query := `INSERT INTO in_app_notifications (user_id, event, title, body, created_at) VALUES (?, ?, ?, ?, ?)`
+	// Stored in UTC so the keyset condition in ListByUser compares correctly.
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, n.UserID, n.Event, n.Title, n.Body, n.CreatedAt.UTC())
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	n.ID = id
+	return nil
+}
+
+func (r *SQLInAppNotificationRepository) ListByUser(
+	ctx context.Context,
+	userID int64,
+	page domain.Page,
+) ([]*domain.InAppNotification, error) {
+	query := `SELECT id, user_id, event, title, body, created_at FROM in_app_notifications WHERE user_id = ?`
+	args := []any{userID}
+	if page.After != nil {
+		after := page.After.CreatedAt.UTC()
+		query += ` AND (created_at < ? OR (created_at = ? AND id < ?))`
+		args = append(args, after, after, page.After.ID)
+	}
+
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append(args, page.Limit, page.Offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*domain.InAppNotification
+	for rows.Next() {
+		var n domain.InAppNotification
+		if scanErr := rows.Scan(&n.ID, &n.UserID, &n.Event, &n.Title, &n.Body, &n.CreatedAt); scanErr != nil {
+			return nil, scanErr
+		}
+		notifications = append(notifications, &n)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, rowsErr
+	}
+
+	return notifications, nil
+}
+
+func (r *SQLInAppNotificationRepository) CountByUser(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM in_app_notifications WHERE user_id = ?`, userID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,64 @@
+package infra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// LineNotifier pushes notifications to the user's bound Line account.
+type LineNotifier struct {
+	client domain.LineClient
+}
+
+func NewLineNotifier(client domain.LineClient) *LineNotifier {
+	return &LineNotifier{client: client}
+}
+
+func (n *LineNotifier) Channel() domain.NotificationChannel {
+	return domain.NotificationChannelLine
+}
+
+func (n *LineNotifier) Send(ctx context.Context, user *domain.User, notification *domain.Notification) error {
+	if user.LineUserID == "" {
+		return fmt.Errorf("%w: user %d has no Line account bound", domain.ErrNotificationUndeliverable, user.ID)
+	}
+
+	message := domain.NewFlexCard(notification.Title).Text(notification.Body).Message(notification.Title)
+	err := n.client.PushMessage(ctx, user.LineUserID, []domain.LineMessage{message})
+
+	// Client errors other than rate limiting will fail the same way on retry.
+	var apiErr *LineAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError &&
+		apiErr.StatusCode != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %w", domain.ErrNotificationUndeliverable, err)
+	}
+	return err
+}
+
+// InAppNotifier stores notifications in the user's web app inbox.
+type InAppNotifier struct {
+	repo domain.InAppNotificationRepository
+}
+
+func NewInAppNotifier(repo domain.InAppNotificationRepository) *InAppNotifier {
+	return &InAppNotifier{repo: repo}
+}
+
+func (n *InAppNotifier) Channel() domain.NotificationChannel {
+	return domain.NotificationChannelInApp
+}
+
+func (n *InAppNotifier) Send(ctx context.Context, user *domain.User, notification *domain.Notification) error {
+	return n.repo.Create(ctx, &domain.InAppNotification{
+		UserID:    user.ID,
+		Event:     notification.Event,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,81 @@
+// Package postgres implements the domain repositories on PostgreSQL, for
+// deployments that outgrow a single SQLite file. It mirrors the SQLite
+// repositories in package infra.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+	"strconv"
+
+	// PostgreSQL driver.
+	_ "github.com/lib/pq"
+
+	"ministry-scheduler/internal/infra"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Dialect is the PostgreSQL flavour of the migration SQL. The lock makes a
+// second server starting at the same time wait instead of racing.
+func Dialect() infra.Dialect {
+	return infra.Dialect{
+		Placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		TableExistsQuery: `SELECT COUNT(*) FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_name = $1`,
+		LockQuery: `LOCK TABLE schema_migrations IN EXCLUSIVE MODE`,
+	}
+}
+
+// InitializeDB connects to databaseURL and applies pending migrations.
+func InitializeDB(databaseURL string) (*sql.DB, error) {
+	db, err := OpenDB(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, migrateErr := Migrate(context.Background(), db); migrateErr != nil {
+		_ = db.Close() // Ignore close error, return the original error
+		return nil, migrateErr
+	}
+
+	return db, nil
+}
+
+// OpenDB connects to databaseURL without migrating. Both URLs
+// (postgres://...) and key=value strings are accepted.
+func OpenDB(databaseURL string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if pingErr := db.PingContext(context.Background()); pingErr != nil {
+		_ = db.Close() // Ignore close error, return the original error
+		return nil, pingErr
+	}
+
+	return db, nil
+}
+
+// Migrations returns the embedded PostgreSQL migrations in version order.
+func Migrations() ([]infra.Migration, error) {
+	return newMigrator(nil).Migrations()
+}
+
+// PendingMigrations reports the migrations Migrate would apply.
+func PendingMigrations(ctx context.Context, db *sql.DB) ([]infra.Migration, error) {
+	return newMigrator(db).Pending(ctx)
+}
+
+func Migrate(ctx context.Context, db *sql.DB) ([]infra.Migration, error) {
+	return newMigrator(db).Migrate(ctx)
+}
+
+func newMigrator(db *sql.DB) *infra.Migrator {
+	files, _ := fs.Sub(migrationFiles, "migrations") // The directory is embedded, so Sub cannot fail
+	return infra.NewMigrator(db, files, Dialect())
+}
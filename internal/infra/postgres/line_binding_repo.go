@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"ministry-scheduler/internal/domain"
+)
+
+type LineBindingCodeRepository struct {
+	db *sql.DB
+}
+
+func NewLineBindingCodeRepository(db *sql.DB) *LineBindingCodeRepository {
+	return &LineBindingCodeRepository{db: db}
+}
+
+func (r *LineBindingCodeRepository) Save(ctx context.Context, code *domain.LineBindingCode) error {
+	query := `INSERT INTO line_binding_codes (code, user_id, expires_at, created_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET
+			code = excluded.code, expires_at = excluded.expires_at, created_at = excluded.created_at`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query,
+		code.Code, code.UserID, code.ExpiresAt.UTC(), code.CreatedAt.UTC())
+	return err
+}
+
+func (r *LineBindingCodeRepository) Consume(ctx context.Context, code string) (*domain.LineBindingCode, error) {
+	query := `DELETE FROM line_binding_codes WHERE code = $1 RETURNING code, user_id, expires_at, created_at`
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, code)
+
+	var bindingCode domain.LineBindingCode
+	err := row.Scan(&bindingCode.Code, &bindingCode.UserID, &bindingCode.ExpiresAt, &bindingCode.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidBindingCode
+		}
+		return nil, err
+	}
+
+	return &bindingCode, nil
+}
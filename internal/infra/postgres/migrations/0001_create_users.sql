@@ -0,0 +1,8 @@
+-- Versions and names mirror the SQLite migrations in internal/infra/migrations.
+CREATE TABLE users (
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	email TEXT UNIQUE NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
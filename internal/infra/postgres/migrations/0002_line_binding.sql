@@ -0,0 +1,12 @@
+CREATE TABLE user_line_accounts (
+	user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	line_user_id TEXT UNIQUE NOT NULL,
+	bound_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE line_binding_codes (
+	code TEXT PRIMARY KEY,
+	user_id BIGINT UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	expires_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
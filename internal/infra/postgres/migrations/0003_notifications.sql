@@ -0,0 +1,34 @@
+CREATE TABLE notification_outbox (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	event TEXT NOT NULL,
+	channel TEXT NOT NULL,
+	title TEXT NOT NULL,
+	body TEXT NOT NULL,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	last_error TEXT NOT NULL DEFAULT '',
+	next_attempt_at TIMESTAMPTZ NOT NULL,
+	locked_until TIMESTAMPTZ,
+	sent_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_notification_outbox_due ON notification_outbox (status, next_attempt_at);
+
+CREATE TABLE in_app_notifications (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	event TEXT NOT NULL,
+	title TEXT NOT NULL,
+	body TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_in_app_notifications_user ON in_app_notifications (user_id, created_at);
+
+CREATE TABLE user_notification_preferences (
+	user_id BIGINT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	preferences TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
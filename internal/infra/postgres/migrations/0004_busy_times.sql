@@ -0,0 +1,22 @@
+CREATE TABLE busy_sources (
+	id BIGSERIAL PRIMARY KEY,
+	user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	kind TEXT NOT NULL,
+	url TEXT NOT NULL DEFAULT '',
+	last_synced_at TIMESTAMPTZ,
+	last_error TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_busy_sources_user ON busy_sources (user_id);
+
+CREATE TABLE busy_blocks (
+	id BIGSERIAL PRIMARY KEY,
+	source_id BIGINT NOT NULL REFERENCES busy_sources(id) ON DELETE CASCADE,
+	user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	starts_at TIMESTAMPTZ NOT NULL,
+	ends_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_busy_blocks_user_time ON busy_blocks (user_id, starts_at);
+CREATE INDEX idx_busy_blocks_source ON busy_blocks (source_id);
@@ -0,0 +1,4 @@
+-- Keyset paging orders by (created_at, id) or (name, id). Names compare
+-- bytewise, as in SQLite, so the order doesn't depend on the server locale.
+CREATE INDEX idx_users_created_at_id ON users (created_at, id);
+CREATE INDEX idx_users_name_id ON users ((name COLLATE "C"), id);
@@ -0,0 +1,3 @@
+-- SQLite rewrites in-app notification timestamps that kept the server's
+-- offset in UTC. TIMESTAMPTZ values are stored as instants already, so there
+-- is nothing to change here; the file keeps the version numbers in step.
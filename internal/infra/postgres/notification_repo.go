@@ -0,0 +1,247 @@
+package postgres
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+const notificationColumns = `id, user_id, event, channel, title, body, status, attempts, last_error,
+	next_attempt_at, sent_at, created_at, locked_until`
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Enqueue(ctx context.Context, notifications []*domain.Notification) error {
+	query := `INSERT INTO notification_outbox
+		(user_id, event, channel, title, body, status, attempts, last_error, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	for _, n := range notifications {
+		err := conn(ctx, r.db).QueryRowContext(ctx, query, n.UserID, n.Event, n.Channel, n.Title, n.Body,
+			n.Status, n.Attempts, n.LastError, n.NextAttemptAt.UTC(), n.CreatedAt.UTC()).Scan(&n.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) GetByID(ctx context.Context, id int64) (*domain.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notification_outbox WHERE id = $1`
+	notification, err := scanNotification(conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrNotificationNotFound
+	}
+	return notification, err
+}
+
+// ClaimDue leases the due rows in a single statement. SKIP LOCKED lets
+// concurrent workers claim different rows instead of waiting on each other.
+func (r *NotificationRepository) ClaimDue(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+	lockedUntil time.Time,
+) ([]*domain.Notification, error) {
+	query := `UPDATE notification_outbox SET locked_until = $1
+		WHERE id IN (
+			SELECT id FROM notification_outbox
+			WHERE status = $2 AND next_attempt_at <= $3 AND (locked_until IS NULL OR locked_until <= $3)
+			ORDER BY next_attempt_at, id LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + notificationColumns
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query,
+		lockedUntil.UTC(), domain.NotificationStatusPending, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := scanNotifications(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not follow the subquery order.
+	slices.SortFunc(notifications, func(a, b *domain.Notification) int {
+		return cmp.Or(a.NextAttemptAt.Compare(b.NextAttemptAt), cmp.Compare(a.ID, b.ID))
+	})
+	return notifications, nil
+}
+
+// UpdateDelivery only updates the row while it still holds the caller's lease:
+// the locked_until that ClaimDue returned, or none.
+func (r *NotificationRepository) UpdateDelivery(ctx context.Context, n *domain.Notification) error {
+	query := `UPDATE notification_outbox
+		SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4, sent_at = $5, locked_until = NULL
+		WHERE id = $6 AND locked_until IS NOT DISTINCT FROM $7`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		n.Status, n.Attempts, n.LastError, n.NextAttemptAt.UTC(), n.SentAt, n.ID, n.LockedUntil)
+	if err != nil {
+		return err
+	}
+
+	if err = requireRowAffected(result, domain.ErrNotificationLeaseLost); err != nil {
+		if errors.Is(err, domain.ErrNotificationLeaseLost) {
+			return r.missingOrLeaseLost(ctx, n.ID)
+		}
+		return err
+	}
+
+	n.LockedUntil = nil
+	return nil
+}
+
+// missingOrLeaseLost explains why UpdateDelivery matched no row.
+func (r *NotificationRepository) missingOrLeaseLost(ctx context.Context, id int64) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM notification_outbox WHERE id = $1)`
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return domain.ErrNotificationLeaseLost
+	}
+	return domain.ErrNotificationNotFound
+}
+
+func (r *NotificationRepository) ListByStatus(
+	ctx context.Context,
+	status domain.NotificationStatus,
+	page domain.Page,
+) ([]*domain.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notification_outbox WHERE status = $1`
+	args := []any{status}
+	if page.After != nil {
+		args = append(args, page.After.ID)
+		query += ` AND id < ` + placeholder(len(args))
+	}
+
+	args = append(args, page.Limit, page.Offset)
+	query += ` ORDER BY id DESC LIMIT ` + placeholder(len(args)-1) + ` OFFSET ` + placeholder(len(args))
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanNotifications(rows)
+}
+
+func (r *NotificationRepository) CountByStatus(ctx context.Context, status domain.NotificationStatus) (int, error) {
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM notification_outbox WHERE status = $1`, status).Scan(&count)
+	return count, err
+}
+
+func scanNotifications(rows *sql.Rows) ([]*domain.Notification, error) {
+	defer rows.Close()
+
+	var notifications []*domain.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, rowsErr
+	}
+
+	return notifications, nil
+}
+
+func scanNotification(row rowScanner) (*domain.Notification, error) {
+	var (
+		n           domain.Notification
+		sentAt      sql.NullTime
+		lockedUntil sql.NullTime
+	)
+	err := row.Scan(&n.ID, &n.UserID, &n.Event, &n.Channel, &n.Title, &n.Body, &n.Status, &n.Attempts,
+		&n.LastError, &n.NextAttemptAt, &sentAt, &n.CreatedAt, &lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	if sentAt.Valid {
+		n.SentAt = &sentAt.Time
+	}
+	if lockedUntil.Valid {
+		n.LockedUntil = &lockedUntil.Time
+	}
+	return &n, nil
+}
+
+type InAppNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewInAppNotificationRepository(db *sql.DB) *InAppNotificationRepository {
+	return &InAppNotificationRepository{db: db}
+}
+
+func (r *InAppNotificationRepository) Create(ctx context.Context, n *domain.InAppNotification) error {
+	query := `INSERT INTO in_app_notifications (user_id, event, title, body, created_at)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	return conn(ctx, r.db).QueryRowContext(ctx, query,
+		n.UserID, n.Event, n.Title, n.Body, n.CreatedAt.UTC()).Scan(&n.ID)
+}
+
+func (r *InAppNotificationRepository) ListByUser(
+	ctx context.Context,
+	userID int64,
+	page domain.Page,
+) ([]*domain.InAppNotification, error) {
+	query := `SELECT id, user_id, event, title, body, created_at FROM in_app_notifications WHERE user_id = $1`
+	args := []any{userID}
+	if page.After != nil {
+		args = append(args, page.After.CreatedAt.UTC(), page.After.ID)
+		after, id := placeholder(len(args)-1), placeholder(len(args))
+		query += fmt.Sprintf(` AND (created_at < %[1]s OR (created_at = %[1]s AND id < %[2]s))`, after, id)
+	}
+
+	args = append(args, page.Limit, page.Offset)
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ` + placeholder(len(args)-1) + ` OFFSET ` + placeholder(len(args))
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*domain.InAppNotification
+	for rows.Next() {
+		var n domain.InAppNotification
+		if scanErr := rows.Scan(&n.ID, &n.UserID, &n.Event, &n.Title, &n.Body, &n.CreatedAt); scanErr != nil {
+			return nil, scanErr
+		}
+		notifications = append(notifications, &n)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, rowsErr
+	}
+
+	return notifications, nil
+}
+
+func (r *InAppNotificationRepository) CountByUser(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM in_app_notifications WHERE user_id = $1`, userID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,278 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// uniqueViolation is the SQLSTATE of a failed UNIQUE constraint.
+const uniqueViolation = "23505"
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// selectUserQuery joins the Line binding and notification preferences so every
+// read returns the full user.
+const selectUserQuery = `SELECT u.id, u.name, u.email, COALESCE(l.line_user_id, ''),
+		COALESCE(p.preferences, '{}'), u.created_at, u.updated_at
+	FROM users u
+	LEFT JOIN user_line_accounts l ON l.user_id = u.id
+	LEFT JOIN user_notification_preferences p ON p.user_id = u.id`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func (r *UserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, selectUserQuery+` WHERE u.id = $1`, id)
+	return scanUser(row)
+}
+
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, selectUserQuery+` WHERE u.email = $1`, email)
+	return scanUser(row)
+}
+
+func (r *UserRepository) GetByLineUserID(ctx context.Context, lineUserID string) (*domain.User, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, selectUserQuery+` WHERE l.line_user_id = $1`, lineUserID)
+	return scanUser(row)
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
+	query := `INSERT INTO users (name, email, created_at, updated_at) VALUES ($1, $2, $3, $4) RETURNING id`
+	err := conn(ctx, r.db).QueryRowContext(ctx, query,
+		user.Name, user.Email, user.CreatedAt.UTC(), user.UpdatedAt.UTC()).Scan(&user.ID)
+	if isUniqueViolation(err) {
+		return nil, domain.ErrUserExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *domain.User) (*domain.User, error) {
+	query := `UPDATE users SET name = $1, email = $2, updated_at = $3 WHERE id = $4`
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, user.Name, user.Email, user.UpdatedAt.UTC(), user.ID)
+	if isUniqueViolation(err) {
+		return nil, domain.ErrUserExists
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = requireRowAffected(result, domain.ErrUserNotFound); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) Delete(ctx context.Context, id int64) error {
+	result, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return requireRowAffected(result, domain.ErrUserNotFound)
+}
+
+func (r *UserRepository) List(
+	ctx context.Context,
+	filter domain.UserFilter,
+	sort domain.UserSort,
+	page domain.Page,
+) ([]*domain.User, error) {
+	column, descending := userSortColumn(sort)
+	direction, operator := "ASC", ">"
+	if descending {
+		direction, operator = "DESC", "<"
+	}
+
+	conditions, args := userFilterConditions(filter)
+	if page.After != nil {
+		var after any = page.After.CreatedAt.UTC()
+		if column != "u.created_at" {
+			after = page.After.Key
+		}
+		args = append(args, after, page.After.ID)
+		key, id := placeholder(len(args)-1), placeholder(len(args))
+		conditions = append(conditions,
+			fmt.Sprintf(`(%[1]s %[2]s %[3]s OR (%[1]s = %[3]s AND u.id %[2]s %[4]s))`, column, operator, key, id))
+	}
+
+	args = append(args, page.Limit, page.Offset)
+	query := selectUserQuery + whereClause(conditions) +
+		fmt.Sprintf(` ORDER BY %[1]s %[2]s, u.id %[2]s LIMIT %[3]s OFFSET %[4]s`,
+			column, direction, placeholder(len(args)-1), placeholder(len(args)))
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*domain.User
+	for rows.Next() {
+		user, scanErr := scanUser(rows)
+		if scanErr != nil {
+			return nil, scanErr
+		}
+		users = append(users, user)
+	}
+
+	if rowsErr := rows.Err(); rowsErr != nil {
+		return nil, rowsErr
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) Count(ctx context.Context, filter domain.UserFilter) (int, error) {
+	conditions, args := userFilterConditions(filter)
+	query := `SELECT COUNT(*) FROM users u` + whereClause(conditions)
+
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// userSortColumn maps sort to the column it orders by. Names use the "C"
+// collation so they sort bytewise, as in SQLite, and match idx_users_name_id.
+func userSortColumn(sort domain.UserSort) (string, bool) {
+	switch sort {
+	case domain.UserSortOldest:
+		return "u.created_at", false
+	case domain.UserSortName:
+		return `(u.name COLLATE "C")`, false
+	case domain.UserSortNameDesc:
+		return `(u.name COLLATE "C")`, true
+	case domain.UserSortNewest:
+		return "u.created_at", true
+	default:
+		return "u.created_at", true
+	}
+}
+
+// userFilterConditions translates filter into SQL conditions numbered from $1.
+// ILIKE runs under the C collation so it folds ASCII letters only, matching
+// SQLite's LIKE.
+func userFilterConditions(filter domain.UserFilter) ([]string, []any) {
+	var conditions []string
+	var args []any
+	if filter.Query != "" {
+		args = append(args, "%"+escapeLike(filter.Query)+"%")
+		p := placeholder(len(args))
+		conditions = append(conditions, `(u.name COLLATE "C" ILIKE `+p+` OR u.email COLLATE "C" ILIKE `+p+`)`)
+	}
+	if filter.Name != "" {
+		args = append(args, "%"+escapeLike(filter.Name)+"%")
+		conditions = append(conditions, `u.name COLLATE "C" ILIKE `+placeholder(len(args)))
+	}
+	if !filter.CreatedFrom.IsZero() {
+		args = append(args, filter.CreatedFrom.UTC())
+		conditions = append(conditions, `u.created_at >= `+placeholder(len(args)))
+	}
+	if !filter.CreatedTo.IsZero() {
+		args = append(args, filter.CreatedTo.UTC())
+		conditions = append(conditions, `u.created_at < `+placeholder(len(args)))
+	}
+	return conditions, args
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return ` WHERE ` + strings.Join(conditions, ` AND `)
+}
+
+func placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// escapeLike escapes LIKE wildcards so s matches literally; backslash is the
+// default LIKE escape character in PostgreSQL.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *UserRepository) BindLineAccount(ctx context.Context, userID int64, lineUserID string) error {
+	query := `INSERT INTO user_line_accounts (user_id, line_user_id, bound_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET line_user_id = excluded.line_user_id, bound_at = excluded.bound_at`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, userID, lineUserID, time.Now().UTC())
+	if isUniqueViolation(err) {
+		return domain.ErrLineAccountBound
+	}
+	return err
+}
+
+func (r *UserRepository) UnbindLineAccount(ctx context.Context, userID int64) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM user_line_accounts WHERE user_id = $1`, userID)
+	return err
+}
+
+func (r *UserRepository) UpdateNotificationPreferences(
+	ctx context.Context,
+	userID int64,
+	preferences domain.NotificationPreferences,
+) error {
+	data, err := json.Marshal(preferences)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO user_notification_preferences (user_id, preferences, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET preferences = excluded.preferences, updated_at = excluded.updated_at`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, userID, string(data), time.Now().UTC())
+	return err
+}
+
+func scanUser(row rowScanner) (*domain.User, error) {
+	var user domain.User
+	var preferences string
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.LineUserID, &preferences, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if unmarshalErr := json.Unmarshal([]byte(preferences), &user.NotificationPreferences); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+
+	return &user, nil
+}
+
+// requireRowAffected returns notFound when an UPDATE or DELETE matched no rows.
+func requireRowAffected(result sql.Result, notFound error) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == uniqueViolation
+}
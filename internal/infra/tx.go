@@ -0,0 +1,51 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txKey struct{}
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type SQLTransactor struct {
+	db *sql.DB
+}
+
+func NewSQLTransactor(db *sql.DB) *SQLTransactor {
+	return &SQLTransactor{db: db}
+}
+
+// WithinTx runs fn in a transaction stored in its context. Nested calls join
+// the outer transaction.
+func (t *SQLTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if fnErr := fn(context.WithValue(ctx, txKey{}, tx)); fnErr != nil {
+		_ = tx.Rollback() // Ignore rollback error, return the original error
+		return fnErr
+	}
+
+	return tx.Commit()
+}
+
+// conn returns the transaction carried by ctx, or db when there is none.
+func conn(ctx context.Context, db *sql.DB) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
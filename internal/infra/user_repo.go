@@ -3,10 +3,13 @@ package infra
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
-	// SQLite driver.
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 
 	"ministry-scheduler/internal/domain"
 )
@@ -19,41 +22,41 @@ func NewSQLUserRepository(db *sql.DB) *SQLUserRepository {
 	return &SQLUserRepository{db: db}
 }
 
-func (r *SQLUserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
-	query := `SELECT id, name, email, created_at, updated_at FROM users WHERE id = ?`
-	row := r.db.QueryRowContext(ctx, query, id)
+// selectUserQuery joins the Line binding and notification preferences so every
+// read returns the full user.
+const selectUserQuery = `SELECT u.id, u.name, u.email, COALESCE(l.line_user_id, ''),
+		COALESCE(p.preferences, '{}'), u.created_at, u.updated_at
+	FROM users u
+	LEFT JOIN user_line_accounts l ON l.user_id = u.id
+	LEFT JOIN user_notification_preferences p ON p.user_id = u.id`
 
-	var user domain.User
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, domain.ErrUserNotFound
-		}
-		return nil, err
-	}
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
-	return &user, nil
+func (r *SQLUserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, selectUserQuery+` WHERE u.id = ?`, id)
+	return scanUser(row)
 }
 
 func (r *SQLUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	query := `SELECT id, name, email, created_at, updated_at FROM users WHERE email = ?`
-	row := r.db.QueryRowContext(ctx, query, email)
-
-	var user domain.User
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, domain.ErrUserNotFound
-		}
-		return nil, err
-	}
+	row := conn(ctx, r.db).QueryRowContext(ctx, selectUserQuery+` WHERE u.email = ?`, email)
+	return scanUser(row)
+}
 
-	return &user, nil
+func (r *SQLUserRepository) GetByLineUserID(ctx context.Context, lineUserID string) (*domain.User, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, selectUserQuery+` WHERE l.line_user_id = ?`, lineUserID)
+	return scanUser(row)
 }
 
 func (r *SQLUserRepository) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
 	query := `INSERT INTO users (name, email, created_at, updated_at) VALUES (?, ?, ?, ?)`
-	result, err := r.db.ExecContext(ctx, query, user.Name, user.Email, user.CreatedAt, user.UpdatedAt)
+	// Stored in UTC so the created_at range filters compare correctly.
+	result, err := conn(ctx, r.db).ExecContext(ctx, query,
+		user.Name, user.Email, user.CreatedAt.UTC(), user.UpdatedAt.UTC())
+	if isUniqueViolation(err) {
+		return nil, domain.ErrUserExists
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -69,17 +72,23 @@ func (r *SQLUserRepository) Create(ctx context.Context, user *domain.User) (*dom
 
 func (r *SQLUserRepository) Update(ctx context.Context, user *domain.User) (*domain.User, error) {
 	query := `UPDATE users SET name = ?, email = ?, updated_at = ? WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, user.Name, user.Email, user.UpdatedAt, user.ID)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, user.Name, user.Email, user.UpdatedAt.UTC(), user.ID)
+	if isUniqueViolation(err) {
+		return nil, domain.ErrUserExists
+	}
 	if err != nil {
 		return nil, err
 	}
+	if err = requireRowAffected(result, domain.ErrUserNotFound); err != nil {
+		return nil, err
+	}
 
 	return user, nil
 }
 
 func (r *SQLUserRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM users WHERE id = ?`
-	result, err := r.db.ExecContext(ctx, query, id)
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
@@ -96,9 +105,32 @@ func (r *SQLUserRepository) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (r *SQLUserRepository) List(ctx context.Context, limit, offset int) ([]*domain.User, error) {
-	query := `SELECT id, name, email, created_at, updated_at FROM users ORDER BY created_at DESC LIMIT ? OFFSET ?`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+func (r *SQLUserRepository) List(
+	ctx context.Context,
+	filter domain.UserFilter,
+	sort domain.UserSort,
+	page domain.Page,
+) ([]*domain.User, error) {
+	column, descending := userSortColumn(sort)
+	direction, operator := "ASC", ">"
+	if descending {
+		direction, operator = "DESC", "<"
+	}
+
+	conditions, args := userFilterConditions(filter)
+	if page.After != nil {
+		var after any = page.After.CreatedAt.UTC()
+		if column == "u.name" {
+			after = page.After.Key
+		}
+		conditions = append(conditions,
+			fmt.Sprintf(`(%[1]s %[2]s ? OR (%[1]s = ? AND u.id %[2]s ?))`, column, operator))
+		args = append(args, after, after, page.After.ID)
+	}
+
+	query := selectUserQuery + whereClause(conditions) +
+		fmt.Sprintf(` ORDER BY %[1]s %[2]s, u.id %[2]s LIMIT ? OFFSET ?`, column, direction)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, append(args, page.Limit, page.Offset)...)
 	if err != nil {
 		return nil, err
 	}
@@ -106,11 +138,11 @@ func (r *SQLUserRepository) List(ctx context.Context, limit, offset int) ([]*dom
 
 	var users []*domain.User
 	for rows.Next() {
-		var user domain.User
-		if scanErr := rows.Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt); scanErr != nil {
+		user, scanErr := scanUser(rows)
+		if scanErr != nil {
 			return nil, scanErr
 		}
-		users = append(users, &user)
+		users = append(users, user)
 	}
 
 	if rowsErr := rows.Err(); rowsErr != nil {
@@ -120,31 +152,154 @@ func (r *SQLUserRepository) List(ctx context.Context, limit, offset int) ([]*dom
 	return users, nil
 }
 
+func (r *SQLUserRepository) Count(ctx context.Context, filter domain.UserFilter) (int, error) {
+	conditions, args := userFilterConditions(filter)
+	query := `SELECT COUNT(*) FROM users u` + whereClause(conditions)
+
+	var count int
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// userSortColumn maps sort to the column it orders by. Each has an index
+// together with id for the keyset condition: idx_users_created_at_id and
+// idx_users_name_id.
+func userSortColumn(sort domain.UserSort) (string, bool) {
+	switch sort {
+	case domain.UserSortOldest:
+		return "u.created_at", false
+	case domain.UserSortName:
+		return "u.name", false
+	case domain.UserSortNameDesc:
+		return "u.name", true
+	case domain.UserSortNewest:
+		return "u.created_at", true
+	default:
+		return "u.created_at", true
+	}
+}
+
+// userFilterConditions translates filter into SQL conditions. Substring
+// matches cannot use an index; the created_at bounds use
+// idx_users_created_at_id.
+func userFilterConditions(filter domain.UserFilter) ([]string, []any) {
+	var conditions []string
+	var args []any
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(filter.Query) + "%"
+		conditions = append(conditions, `(u.name LIKE ? ESCAPE '\' OR u.email LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+	if filter.Name != "" {
+		conditions = append(conditions, `u.name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(filter.Name)+"%")
+	}
+	if !filter.CreatedFrom.IsZero() {
+		conditions = append(conditions, `u.created_at >= ?`)
+		args = append(args, filter.CreatedFrom.UTC())
+	}
+	if !filter.CreatedTo.IsZero() {
+		conditions = append(conditions, `u.created_at < ?`)
+		args = append(args, filter.CreatedTo.UTC())
+	}
+	return conditions, args
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return ` WHERE ` + strings.Join(conditions, ` AND `)
+}
+
+// escapeLike escapes LIKE wildcards so s matches literally with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *SQLUserRepository) BindLineAccount(ctx context.Context, userID int64, lineUserID string) error {
+	query := `INSERT INTO user_line_accounts (user_id, line_user_id, bound_at) VALUES (?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET line_user_id = excluded.line_user_id, bound_at = excluded.bound_at`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, userID, lineUserID, time.Now())
+	if isUniqueViolation(err) {
+		return domain.ErrLineAccountBound
+	}
+	return err
+}
+
+func (r *SQLUserRepository) UnbindLineAccount(ctx context.Context, userID int64) error {
+	query := `DELETE FROM user_line_accounts WHERE user_id = ?`
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, userID)
+	return err
+}
+
+func (r *SQLUserRepository) UpdateNotificationPreferences(
+	ctx context.Context,
+	userID int64,
+	preferences domain.NotificationPreferences,
+) error {
+	data, err := json.Marshal(preferences)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO user_notification_preferences (user_id, preferences, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET preferences = excluded.preferences, updated_at = excluded.updated_at`
+	_, err = conn(ctx, r.db).ExecContext(ctx, query, userID, string(data), time.Now())
+	return err
+}
+
+func scanUser(row rowScanner) (*domain.User, error) {
+	var user domain.User
+	var preferences string
+	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.LineUserID, &preferences, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if unmarshalErr := json.Unmarshal([]byte(preferences), &user.NotificationPreferences); unmarshalErr != nil {
+		return nil, unmarshalErr
+	}
+
+	return &user, nil
+}
+
+// InitializeDB opens the database and applies pending migrations.
 func InitializeDB(dbPath string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := OpenDB(dbPath)
 	if err != nil {
 		return nil, err
 	}
 
-	ctx := context.Background()
-	if createErr := createTable(ctx, db); createErr != nil {
+	if _, migrateErr := Migrate(context.Background(), db); migrateErr != nil {
 		_ = db.Close() // Ignore close error, return the original error
-		return nil, createErr
+		return nil, migrateErr
 	}
 
 	return db, nil
 }
 
-func createTable(ctx context.Context, db *sql.DB) error {
-	query := `
-	CREATE TABLE IF NOT EXISTS users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		email TEXT UNIQUE NOT NULL,
-		created_at DATETIME NOT NULL,
-		updated_at DATETIME NOT NULL
-	)`
+// OpenDB opens the database without migrating it.
+func OpenDB(dbPath string) (*sql.DB, error) {
+	return sql.Open("sqlite3", sqliteDSN(dbPath))
+}
 
-	_, err := db.ExecContext(ctx, query)
-	return err
+// sqliteDSN turns on foreign key enforcement so dependent rows are removed
+// together with their user, and makes concurrent writers (HTTP handlers and
+// notification workers) wait for the write lock instead of failing.
+func sqliteDSN(dbPath string) string {
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
+	}
+	return dbPath + separator + "_foreign_keys=on&_busy_timeout=5000&_txlock=immediate"
+}
+
+// isUniqueViolation reports whether err is a failed UNIQUE constraint.
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
 }
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// BusyTimeUsecase imports members' personal calendars as busy blocks. The
+// blocks are soft unavailability: they are meant to warn about conflicts, not
+// to block an assignment the way approved leave does.
+type BusyTimeUsecase struct {
+	repo    domain.BusyTimeRepository
+	users   domain.UserRepository
+	tx      domain.Transactor
+	fetcher domain.CalendarFetcher
+	logger  *slog.Logger
+}
+
+func NewBusyTimeUsecase(
+	repo domain.BusyTimeRepository,
+	users domain.UserRepository,
+	tx domain.Transactor,
+	fetcher domain.CalendarFetcher,
+	logger *slog.Logger,
+) *BusyTimeUsecase {
+	return &BusyTimeUsecase{repo: repo, users: users, tx: tx, fetcher: fetcher, logger: logger}
+}
+
+// ImportCalendar stores the busy blocks of an uploaded ICS file as a new source.
+func (u *BusyTimeUsecase) ImportCalendar(ctx context.Context, userID int64, data []byte) (*domain.BusySource, error) {
+	if _, err := u.users.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return u.createSource(ctx, &domain.BusySource{UserID: userID, Kind: domain.BusySourceUpload}, data)
+}
+
+// Subscribe registers a private ICS URL and imports it straight away, so a
+// URL that cannot be fetched or parsed is rejected.
+func (u *BusyTimeUsecase) Subscribe(ctx context.Context, userID int64, rawURL string) (*domain.BusySource, error) {
+	if _, err := u.users.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	calendarURL, err := normalizeCalendarURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := u.fetch(ctx, userID, calendarURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.createSource(ctx, &domain.BusySource{UserID: userID, Kind: domain.BusySourceURL, URL: calendarURL}, data)
+}
+
+// RefreshSubscriptions fetches every subscribed URL again. A failing source
+// keeps its previous blocks and records the error.
+func (u *BusyTimeUsecase) RefreshSubscriptions(ctx context.Context) error {
+	sources, err := u.repo.ListURLSources(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, source := range sources {
+		if refreshErr := u.refresh(ctx, source); refreshErr != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			u.logger.WarnContext(ctx, "failed to refresh busy time subscription",
+				"source_id", source.ID, "user_id", source.UserID, "error", refreshErr)
+		}
+	}
+
+	return nil
+}
+
+// Run refreshes subscriptions every interval until ctx is cancelled.
+func (u *BusyTimeUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := u.RefreshSubscriptions(ctx); err != nil && ctx.Err() == nil {
+			u.logger.ErrorContext(ctx, "failed to refresh busy time subscriptions", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *BusyTimeUsecase) ListSources(ctx context.Context, userID int64) ([]*domain.BusySource, error) {
+	if _, err := u.users.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListSources(ctx, userID)
+}
+
+func (u *BusyTimeUsecase) DeleteSource(ctx context.Context, userID, sourceID int64) error {
+	source, err := u.repo.GetSource(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	if source.UserID != userID {
+		return domain.ErrBusySourceNotFound
+	}
+
+	return u.repo.DeleteSource(ctx, sourceID)
+}
+
+// ListBusy returns the user's busy blocks that overlap [from, to).
+func (u *BusyTimeUsecase) ListBusy(ctx context.Context, userID int64, from, to time.Time) ([]domain.BusyBlock, error) {
+	if _, err := u.users.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return u.repo.ListBlocks(ctx, userID, from, to)
+}
+
+func (u *BusyTimeUsecase) createSource(
+	ctx context.Context,
+	source *domain.BusySource,
+	data []byte,
+) (*domain.BusySource, error) {
+	now := time.Now()
+	blocks, err := parseBusyBlocks(data, source.UserID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	source.LastSyncedAt = &now
+	source.CreatedAt = now
+
+	txErr := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		created, createErr := u.repo.CreateSource(ctx, source)
+		if createErr != nil {
+			return createErr
+		}
+		return u.repo.ReplaceBlocks(ctx, created.ID, withSource(blocks, created.ID))
+	})
+	if txErr != nil {
+		return nil, txErr
+	}
+
+	return source, nil
+}
+
+func (u *BusyTimeUsecase) refresh(ctx context.Context, source *domain.BusySource) error {
+	now := time.Now()
+	data, err := u.fetch(ctx, source.UserID, source.URL)
+	var blocks []domain.BusyBlock
+	if err == nil {
+		blocks, err = parseBusyBlocks(data, source.UserID, now)
+	}
+
+	if err != nil {
+		source.LastError = domain.ErrCalendarUnavailable.Error()
+		if errors.Is(err, domain.ErrInvalidCalendar) {
+			source.LastError = domain.ErrInvalidCalendar.Error()
+		}
+		if updateErr := u.repo.UpdateSourceSync(ctx, source); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+
+	source.LastSyncedAt = &now
+	source.LastError = ""
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if replaceErr := u.repo.ReplaceBlocks(ctx, source.ID, withSource(blocks, source.ID)); replaceErr != nil {
+			return replaceErr
+		}
+		return u.repo.UpdateSourceSync(ctx, source)
+	})
+}
+
+// fetch downloads a subscribed calendar. Fetch errors can describe the
+// network behind the server, so they are only logged; callers get a generic
+// error. The log names only the host: the rest of a subscription URL is
+// usually the calendar's secret token.
+func (u *BusyTimeUsecase) fetch(ctx context.Context, userID int64, calendarURL string) ([]byte, error) {
+	data, err := u.fetcher.Fetch(ctx, calendarURL)
+	if err == nil {
+		return data, nil
+	}
+
+	host := ""
+	if parsed, parseErr := url.Parse(calendarURL); parseErr == nil {
+		host = parsed.Host
+	}
+	u.logger.WarnContext(ctx, "failed to fetch calendar",
+		"user_id", userID, "host", host, "error", withoutURL(err))
+	if errors.Is(err, domain.ErrInvalidCalendar) {
+		return nil, domain.ErrInvalidCalendar
+	}
+	return nil, domain.ErrCalendarUnavailable
+}
+
+// withoutURL drops the request URL that *url.Error quotes in its message.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+func parseBusyBlocks(data []byte, userID int64, now time.Time) ([]domain.BusyBlock, error) {
+	blocks, err := domain.ParseBusyBlocks(data, now.Add(-domain.BusyTimeLookback), now.Add(domain.BusyTimeHorizon))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range blocks {
+		blocks[i].UserID = userID
+	}
+	return blocks, nil
+}
+
+func withSource(blocks []domain.BusyBlock, sourceID int64) []domain.BusyBlock {
+	for i := range blocks {
+		blocks[i].SourceID = sourceID
+	}
+	return blocks
+}
+
+// normalizeCalendarURL accepts http, https and webcal URLs; webcal is fetched
+// over https.
+func normalizeCalendarURL(rawURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Host == "" {
+		return "", domain.ErrInvalidCalendarURL
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+	case "webcal":
+		parsed.Scheme = "https"
+	default:
+		return "", domain.ErrInvalidCalendarURL
+	}
+
+	return parsed.String(), nil
+}
@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// bindingCodeAlphabet leaves out characters that are easy to mistype (0/O, 1/I).
+const bindingCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const (
+	lineBoundTitle   = "Line 帳號已綁定"
+	lineBoundBody    = "你的帳號已綁定 Line，之後將透過 Line 接收服事通知。如果這不是你本人的操作，請立即解除綁定。"
+	lineUnboundTitle = "Line 帳號已解除綁定"
+	lineUnboundBody  = "你的帳號已解除 Line 綁定，將不再透過 Line 接收服事通知。"
+)
+
+type LineBindingUsecase struct {
+	users         domain.UserRepository
+	codes         domain.LineBindingCodeRepository
+	tx            domain.Transactor
+	notifications *NotificationUsecase
+}
+
+func NewLineBindingUsecase(
+	users domain.UserRepository,
+	codes domain.LineBindingCodeRepository,
+	tx domain.Transactor,
+	notifications *NotificationUsecase,
+) *LineBindingUsecase {
+	return &LineBindingUsecase{
+		users:         users,
+		codes:         codes,
+		tx:            tx,
+		notifications: notifications,
+	}
+}
+
+func (u *LineBindingUsecase) GenerateCode(ctx context.Context, userID int64) (*domain.LineBindingCode, error) {
+	if _, err := u.users.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	value, err := generateBindingCode()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	code := &domain.LineBindingCode{
+		Code:      value,
+		UserID:    userID,
+		ExpiresAt: now.Add(domain.LineBindingCodeTTL),
+		CreatedAt: now,
+	}
+
+	if saveErr := u.codes.Save(ctx, code); saveErr != nil {
+		return nil, saveErr
+	}
+
+	return code, nil
+}
+
+// Bind links lineUserID to the user who generated code. Binding a user who is
+// already linked to another Line account replaces the old binding. The code
+// is consumed in the same transaction as the binding, so a bind that fails,
+// e.g. because the Line account belongs to someone else, leaves it usable.
+func (u *LineBindingUsecase) Bind(ctx context.Context, lineUserID, code string) (*domain.User, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != domain.LineBindingCodeLength {
+		return nil, domain.ErrInvalidBindingCode
+	}
+
+	var user *domain.User
+	err := u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		existingUser, err := u.users.GetByLineUserID(ctx, lineUserID)
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			return err
+		}
+
+		bindingCode, err := u.codes.Consume(ctx, code)
+		if err != nil {
+			return err
+		}
+		if bindingCode.IsExpired(time.Now()) {
+			return domain.ErrInvalidBindingCode
+		}
+		if existingUser != nil {
+			if existingUser.ID != bindingCode.UserID {
+				return domain.ErrLineAccountBound
+			}
+			user = existingUser
+			return nil
+		}
+
+		if user, err = u.users.GetByID(ctx, bindingCode.UserID); err != nil {
+			return err
+		}
+		if err = u.users.BindLineAccount(ctx, user.ID, lineUserID); err != nil {
+			return err
+		}
+		user.LineUserID = lineUserID
+		return u.notifications.Enqueue(ctx, user.ID, domain.NotificationEventLineBound, lineBoundTitle, lineBoundBody)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (u *LineBindingUsecase) Unbind(ctx context.Context, userID int64) error {
+	user, err := u.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.LineUserID == "" {
+		return domain.ErrLineNotBound
+	}
+
+	return u.unbind(ctx, user.ID)
+}
+
+func (u *LineBindingUsecase) UnbindLineUser(ctx context.Context, lineUserID string) error {
+	user, err := u.GetBoundUser(ctx, lineUserID)
+	if err != nil {
+		return err
+	}
+
+	return u.unbind(ctx, user.ID)
+}
+
+func (u *LineBindingUsecase) GetBoundUser(ctx context.Context, lineUserID string) (*domain.User, error) {
+	user, err := u.users.GetByLineUserID(ctx, lineUserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, domain.ErrLineNotBound
+	}
+	return user, err
+}
+
+func (u *LineBindingUsecase) unbind(ctx context.Context, userID int64) error {
+	return u.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.users.UnbindLineAccount(ctx, userID); err != nil {
+			return err
+		}
+		return u.notifications.Enqueue(ctx, userID, domain.NotificationEventLineUnbound, lineUnboundTitle, lineUnboundBody)
+	})
+}
+
+func generateBindingCode() (string, error) {
+	buf := make([]byte, domain.LineBindingCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	// 256 is a multiple of the alphabet size, so the modulo keeps every
+	// character equally likely.
+	for i, b := range buf {
+		buf[i] = bindingCodeAlphabet[int(b)%len(bindingCodeAlphabet)]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ministry-scheduler/internal/domain"
+)
+
+const (
+	lineBindCommand   = "綁定"
+	lineUnbindCommand = "解除綁定"
+
+	lineWelcomeText       = "歡迎使用服事小秘書！"
+	lineBindHintText      = "請先在網頁產生綁定碼，再傳送「綁定 綁定碼」完成 Line 帳號綁定。"
+	lineUnknownActionText = "無法辨識的操作，請重新選擇。"
+	lineBindSuccessText   = "綁定成功！%s，之後將透過 Line 通知你的服事安排。"
+	lineInvalidCodeText   = "綁定碼無效或已過期，請重新產生綁定碼。"
+	lineBoundElsewhere    = "此 Line 帳號已綁定其他成員，請先解除綁定。"
+	lineUnbindSuccessText = "已解除 Line 帳號綁定。"
+	lineNotBoundText      = "此 Line 帳號尚未綁定。"
+)
+
+// LineBotUsecase turns Line webhook events into the messages the bot replies with.
+type LineBotUsecase struct {
+	binding  *LineBindingUsecase
+	schedule *ScheduleQueryUsecase
+}
+
+func NewLineBotUsecase(binding *LineBindingUsecase, schedule *ScheduleQueryUsecase) *LineBotUsecase {
+	return &LineBotUsecase{binding: binding, schedule: schedule}
+}
+
+func (u *LineBotUsecase) HandleFollow(ctx context.Context, lineUserID string) ([]domain.LineMessage, error) {
+	_, err := u.binding.GetBoundUser(ctx, lineUserID)
+	if errors.Is(err, domain.ErrLineNotBound) {
+		return textMessages(lineWelcomeText, lineBindHintText), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return textMessages(lineWelcomeText), nil
+}
+
+func (u *LineBotUsecase) HandleTextMessage(ctx context.Context, lineUserID, text string) ([]domain.LineMessage, error) {
+	text = strings.TrimSpace(text)
+
+	// Only "綁定 <code>" is a bind command; other text that starts with 綁定,
+	// such as a question about binding, is not.
+	fields := strings.Fields(text)
+	switch {
+	case text == lineUnbindCommand:
+		return u.unbind(ctx, lineUserID)
+	case text == lineBindCommand:
+		return textMessages(lineBindHintText), nil
+	case len(fields) == 2 && fields[0] == lineBindCommand:
+		return u.bind(ctx, lineUserID, fields[1])
+	}
+
+	// The user's timezone decides what 今天 means, so look them up first;
+	// questions from unbound accounts still get the bind hint.
+	user, err := u.binding.GetBoundUser(ctx, lineUserID)
+	if err != nil && !errors.Is(err, domain.ErrLineNotBound) {
+		return nil, err
+	}
+	query, err := u.schedule.Resolve(user, text)
+	switch {
+	case err != nil:
+		return lineHelpMessages(), nil
+	case user == nil:
+		return textMessages(lineBindHintText), nil
+	}
+	return answerScheduleQuery(user, query), nil
+}
+
+// HandlePostback answers button presses. No card the bot sends has postback
+// buttons yet, and the rich menu commands are not implemented, so every
+// action is answered as unknown.
+func (u *LineBotUsecase) HandlePostback(_ context.Context, _, _ string) ([]domain.LineMessage, error) {
+	return textMessages(lineUnknownActionText), nil
+}
+
+func (u *LineBotUsecase) bind(ctx context.Context, lineUserID, code string) ([]domain.LineMessage, error) {
+	user, err := u.binding.Bind(ctx, lineUserID, code)
+	switch {
+	case errors.Is(err, domain.ErrInvalidBindingCode), errors.Is(err, domain.ErrUserNotFound):
+		return textMessages(lineInvalidCodeText), nil
+	case errors.Is(err, domain.ErrLineAccountBound):
+		return textMessages(lineBoundElsewhere), nil
+	case err != nil:
+		return nil, err
+	}
+
+	return textMessages(fmt.Sprintf(lineBindSuccessText, user.Name)), nil
+}
+
+func (u *LineBotUsecase) unbind(ctx context.Context, lineUserID string) ([]domain.LineMessage, error) {
+	err := u.binding.UnbindLineUser(ctx, lineUserID)
+	if errors.Is(err, domain.ErrLineNotBound) {
+		return textMessages(lineNotBoundText), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return textMessages(lineUnbindSuccessText), nil
+}
+
+// LineBindMessage is the chat message that binds a Line account with code.
+func LineBindMessage(code string) string {
+	return lineBindCommand + " " + code
+}
+
+func textMessages(texts ...string) []domain.LineMessage {
+	messages := make([]domain.LineMessage, 0, len(texts))
+	for _, text := range texts {
+		messages = append(messages, domain.NewLineTextMessage(text))
+	}
+	return messages
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// Postback actions of the rich menu. The menu is defined here, but none of
+// its commands is implemented: they need the roster, leave, swap and
+// announcement modules, which don't exist yet. Until they do, the bot sends
+// no menu and lists the commands as coming soon in its help text.
+const (
+	LineActionMySchedule    = "my_schedule"
+	LineActionTeamSchedule  = "team_schedule"
+	LineActionRequestLeave  = "request_leave"
+	LineActionRequestSwap   = "request_swap"
+	LineActionAnnouncements = "announcements"
+	LineActionLiveStatus    = "live_status"
+)
+
+const (
+	lineHelpText        = "傳送「綁定 綁定碼」綁定 Line 帳號，或傳送「解除綁定」解除綁定。"
+	lineComingSoonText  = "服事表發佈後將開放："
+	lineUnavailableText = "此功能需要已發佈的服事表，目前尚未開放。"
+	lineMemberLabel     = "成員"
+	linePeriodLabel     = "期間"
+	lineCoServersTitle  = "👥 一起服事的同工"
+)
+
+type lineCommand struct {
+	action string
+	title  string
+}
+
+func lineCommands() []lineCommand {
+	return []lineCommand{
+		{action: LineActionMySchedule, title: "📅 我的服事"},
+		{action: LineActionTeamSchedule, title: "📊 團隊服事"},
+		{action: LineActionRequestLeave, title: "🙋 我要請假"},
+		{action: LineActionRequestSwap, title: "🔄 換服事"},
+		{action: LineActionAnnouncements, title: "🔔 公告"},
+		{action: LineActionLiveStatus, title: "📡 即時狀態"},
+	}
+}
+
+// LinePostbackData encodes a postback action the way HandlePostback expects it.
+func LinePostbackData(action string) string {
+	return url.Values{"action": {action}}.Encode()
+}
+
+// answerScheduleQuery replies to a question parsed from free text with a card
+// titled like the matching menu command and the resolved period. The roster
+// isn't modelled yet, so the card only says it is not available.
+func answerScheduleQuery(user *domain.User, query *domain.ScheduleQuery) []domain.LineMessage {
+	title := scheduleQueryTitle(query.Intent)
+	card := domain.NewFlexCard(title).
+		Field(lineMemberLabel, user.Name).
+		Field(linePeriodLabel, formatSchedulePeriod(query)).
+		Text(lineUnavailableText)
+	return []domain.LineMessage{card.Message(title)}
+}
+
+func scheduleQueryTitle(intent domain.ScheduleIntent) string {
+	action := LineActionMySchedule
+	switch intent {
+	case domain.ScheduleIntentMine:
+	case domain.ScheduleIntentTeam:
+		action = LineActionTeamSchedule
+	case domain.ScheduleIntentCoServers:
+		return lineCoServersTitle
+	}
+
+	command, _ := findLineCommand(action)
+	return command.title
+}
+
+// formatSchedulePeriod renders a query range as "10/20（一）" or
+// "10/20（一）– 10/26（日）".
+func formatSchedulePeriod(query *domain.ScheduleQuery) string {
+	last := query.To.AddDate(0, 0, -1)
+	if !last.After(query.From) {
+		return formatScheduleDay(query.From)
+	}
+	return formatScheduleDay(query.From) + " – " + formatScheduleDay(last)
+}
+
+func formatScheduleDay(day time.Time) string {
+	weekdays := []string{"日", "一", "二", "三", "四", "五", "六"}
+	return fmt.Sprintf("%d/%d（%s）", day.Month(), day.Day(), weekdays[day.Weekday()])
+}
+
+// lineHelpMessages explains what the bot understands today.
+func lineHelpMessages() []domain.LineMessage {
+	titles := make([]string, 0, len(lineCommands()))
+	for _, command := range lineCommands() {
+		titles = append(titles, command.title)
+	}
+	return textMessages(lineHelpText, lineComingSoonText+strings.Join(titles, "、"))
+}
+
+func findLineCommand(action string) (lineCommand, bool) {
+	for _, command := range lineCommands() {
+		if command.action == action {
+			return command, true
+		}
+	}
+	return lineCommand{}, false
+}
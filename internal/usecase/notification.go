@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+const (
+	notificationBatchSize = 20
+	notificationLease     = 2 * time.Minute
+)
+
+// NotificationUsecase writes notifications to the outbox and delivers them
+// through the registered notifiers.
+type NotificationUsecase struct {
+	repo      domain.NotificationRepository
+	inApp     domain.InAppNotificationRepository
+	users     domain.UserRepository
+	notifiers map[domain.NotificationChannel]domain.Notifier
+	logger    *slog.Logger
+}
+
+func NewNotificationUsecase(
+	repo domain.NotificationRepository,
+	inApp domain.InAppNotificationRepository,
+	users domain.UserRepository,
+	logger *slog.Logger,
+	notifiers ...domain.Notifier,
+) *NotificationUsecase {
+	byChannel := make(map[domain.NotificationChannel]domain.Notifier, len(notifiers))
+	for _, notifier := range notifiers {
+		byChannel[notifier.Channel()] = notifier
+	}
+
+	return &NotificationUsecase{
+		repo:      repo,
+		inApp:     inApp,
+		users:     users,
+		notifiers: byChannel,
+		logger:    logger,
+	}
+}
+
+// Enqueue adds one outbox entry per channel the user receives event on. Call
+// it with the context of the transaction that makes the domain change so both
+// commit together.
+func (u *NotificationUsecase) Enqueue(
+	ctx context.Context,
+	userID int64,
+	event domain.NotificationEvent,
+	title, body string,
+) error {
+	user, err := u.users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	var notifications []*domain.Notification
+	for _, channel := range user.NotificationPreferences.ChannelsFor(event) {
+		if _, ok := u.notifiers[channel]; !ok {
+			continue
+		}
+		notifications = append(notifications, &domain.Notification{
+			UserID:        userID,
+			Event:         event,
+			Channel:       channel,
+			Title:         title,
+			Body:          body,
+			Status:        domain.NotificationStatusPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+
+	if len(notifications) == 0 {
+		return nil
+	}
+	return u.repo.Enqueue(ctx, notifications)
+}
+
+// DispatchDue delivers one batch of due notifications and reports how many
+// were claimed. Send failures are recorded on their notification; a row whose
+// result can't be saved is logged and skipped, and is claimed again once its
+// lease expires, so one bad row doesn't hold up the rest of the batch.
+func (u *NotificationUsecase) DispatchDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	notifications, err := u.repo.ClaimDue(ctx, now, notificationBatchSize, now.Add(notificationLease))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, notification := range notifications {
+		if ctx.Err() != nil {
+			return len(notifications), ctx.Err()
+		}
+
+		err = u.deliver(ctx, notification)
+		switch {
+		case errors.Is(err, domain.ErrNotificationLeaseLost):
+			// Another worker claimed it after our lease expired; its result wins.
+			u.logger.WarnContext(ctx, "notification lease lost", "id", notification.ID)
+		case err != nil:
+			u.logger.ErrorContext(ctx, "failed to record notification delivery",
+				"id", notification.ID, "channel", notification.Channel, "error", err)
+		}
+	}
+
+	return len(notifications), nil
+}
+
+// Run dispatches notifications until ctx is cancelled. Several workers may run
+// concurrently; claimed notifications are leased to one worker at a time.
+func (u *NotificationUsecase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := u.DispatchDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			u.logger.ErrorContext(ctx, "failed to dispatch notifications", "error", err)
+		}
+
+		// A full batch means more may be waiting, so keep going without sleeping.
+		if claimed == notificationBatchSize && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListDeadLetters returns a page of dead-lettered notifications, newest
+// first, and how many there are. When page.After is set the offset is
+// ignored.
+func (u *NotificationUsecase) ListDeadLetters(
+	ctx context.Context,
+	page domain.Page,
+) (*domain.NotificationPage, error) {
+	if page.After != nil && page.After.Sort != domain.NotificationSortNewest {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	page, limit := pageWithLookahead(page)
+	notifications, err := u.repo.ListByStatus(ctx, domain.NotificationStatusDead, page)
+	if err != nil {
+		return nil, err
+	}
+	total, err := u.repo.CountByStatus(ctx, domain.NotificationStatusDead)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.NotificationPage{Notifications: notifications, Total: total}
+	if len(notifications) > limit {
+		result.Notifications = notifications[:limit]
+		last := result.Notifications[limit-1]
+		result.NextCursor = domain.Cursor{
+			Sort: domain.NotificationSortNewest, CreatedAt: last.CreatedAt, ID: last.ID,
+		}.Encode()
+	}
+	result.Count = len(result.Notifications)
+	return result, nil
+}
+
+// Retry moves a dead-lettered notification back to the outbox.
+func (u *NotificationUsecase) Retry(ctx context.Context, id int64) (*domain.Notification, error) {
+	notification, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if notification.Status != domain.NotificationStatusDead {
+		return nil, domain.ErrNotificationNotDead
+	}
+
+	notification.Status = domain.NotificationStatusPending
+	notification.Attempts = 0
+	notification.LastError = ""
+	notification.NextAttemptAt = time.Now()
+
+	if updateErr := u.repo.UpdateDelivery(ctx, notification); updateErr != nil {
+		return nil, updateErr
+	}
+	return notification, nil
+}
+
+// ListInApp returns a page of the user's inbox, newest first, and how many
+// notifications it holds. When page.After is set the offset is ignored.
+func (u *NotificationUsecase) ListInApp(
+	ctx context.Context,
+	userID int64,
+	page domain.Page,
+) (*domain.InAppNotificationPage, error) {
+	if page.After != nil && page.After.Sort != domain.InAppNotificationSortNewest {
+		return nil, domain.ErrInvalidCursor
+	}
+	if _, err := u.users.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	page, limit := pageWithLookahead(page)
+	notifications, err := u.inApp.ListByUser(ctx, userID, page)
+	if err != nil {
+		return nil, err
+	}
+	total, err := u.inApp.CountByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.InAppNotificationPage{Notifications: notifications, Total: total}
+	if len(notifications) > limit {
+		result.Notifications = notifications[:limit]
+		last := result.Notifications[limit-1]
+		result.NextCursor = domain.Cursor{
+			Sort: domain.InAppNotificationSortNewest, CreatedAt: last.CreatedAt, ID: last.ID,
+		}.Encode()
+	}
+	result.Count = len(result.Notifications)
+	return result, nil
+}
+
+func (u *NotificationUsecase) GetPreferences(
+	ctx context.Context,
+	userID int64,
+) (*domain.NotificationPreferences, error) {
+	user, err := u.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &user.NotificationPreferences, nil
+}
+
+func (u *NotificationUsecase) UpdatePreferences(
+	ctx context.Context,
+	userID int64,
+	preferences *domain.NotificationPreferences,
+) (*domain.NotificationPreferences, error) {
+	if err := preferences.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := u.users.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := u.users.UpdateNotificationPreferences(ctx, userID, *preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (u *NotificationUsecase) deliver(ctx context.Context, notification *domain.Notification) error {
+	now := time.Now()
+	notifier, user, sendErr := u.recipient(ctx, notification)
+	if sendErr == nil {
+		// Quiet hours only move the notification; they don't count as an attempt.
+		if until, quiet := user.NotificationPreferences.QuietUntil(notification.Channel, now); quiet {
+			notification.NextAttemptAt = until
+			return u.repo.UpdateDelivery(ctx, notification)
+		}
+		sendErr = notifier.Send(ctx, user, notification)
+	}
+
+	now = time.Now()
+	notification.Attempts++
+
+	switch {
+	case sendErr == nil:
+		notification.Status = domain.NotificationStatusSent
+		notification.LastError = ""
+		notification.SentAt = &now
+	case errors.Is(sendErr, domain.ErrNotificationUndeliverable), notification.Attempts >= domain.MaxNotificationAttempts:
+		notification.Status = domain.NotificationStatusDead
+		notification.LastError = sendErr.Error()
+	default:
+		notification.LastError = sendErr.Error()
+		notification.NextAttemptAt = now.Add(domain.NotificationBackoff(notification.Attempts))
+	}
+
+	if sendErr != nil {
+		u.logger.WarnContext(ctx, "notification delivery failed",
+			"id", notification.ID, "channel", notification.Channel, "attempts", notification.Attempts,
+			"status", notification.Status, "error", sendErr)
+	}
+
+	return u.repo.UpdateDelivery(ctx, notification)
+}
+
+func (u *NotificationUsecase) recipient(
+	ctx context.Context,
+	notification *domain.Notification,
+) (domain.Notifier, *domain.User, error) {
+	notifier, ok := u.notifiers[notification.Channel]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: no notifier for %s", domain.ErrNotificationUndeliverable, notification.Channel)
+	}
+
+	user, err := u.users.GetByID(ctx, notification.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil, fmt.Errorf("%w: %w", domain.ErrNotificationUndeliverable, err)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return notifier, user, nil
+}
@@ -0,0 +1,26 @@
+package usecase
+
+import "ministry-scheduler/internal/domain"
+
+const (
+	defaultListLimit = 10
+	maxListLimit     = 100
+)
+
+// pageWithLookahead applies the default and maximum limit, drops the offset
+// when paging by cursor, and asks for one row more than the returned limit
+// so callers can tell whether there is a next page.
+func pageWithLookahead(page domain.Page) (domain.Page, int) {
+	if page.Limit <= 0 {
+		page.Limit = defaultListLimit
+	}
+	if page.Limit > maxListLimit {
+		page.Limit = maxListLimit
+	}
+	if page.Offset < 0 || page.After != nil {
+		page.Offset = 0
+	}
+	limit := page.Limit
+	page.Limit++
+	return page, limit
+}
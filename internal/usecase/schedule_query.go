@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+type ScheduleQueryUsecase struct {
+	users domain.UserRepository
+	zone  *time.Location
+	now   func() time.Time
+}
+
+// NewScheduleQueryUsecase creates the usecase. zone is used for users who
+// haven't set a timezone; now is the clock, time.Now outside tests.
+func NewScheduleQueryUsecase(
+	users domain.UserRepository,
+	zone *time.Location,
+	now func() time.Time,
+) *ScheduleQueryUsecase {
+	return &ScheduleQueryUsecase{users: users, zone: zone, now: now}
+}
+
+// Query resolves a free-text roster question asked by userID.
+func (u *ScheduleQueryUsecase) Query(ctx context.Context, userID int64, text string) (*domain.ScheduleQuery, error) {
+	user, err := u.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return u.Resolve(user, text)
+}
+
+// Resolve parses text asked by user, which may be nil for someone unknown.
+// Relative dates such as 今天 and 下週 are resolved in the user's timezone,
+// taken from their quiet hours, or in the default zone.
+func (u *ScheduleQueryUsecase) Resolve(user *domain.User, text string) (*domain.ScheduleQuery, error) {
+	zone := u.zone
+	if user != nil {
+		zone = user.NotificationPreferences.Location(u.zone)
+	}
+	return domain.ParseScheduleQuery(text, u.now().In(zone))
+}
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"ministry-scheduler/internal/domain"
@@ -86,21 +87,45 @@ func (u *UserUsecase) DeleteUser(ctx context.Context, id int64) error {
 	return u.repo.Delete(ctx, id)
 }
 
-const (
-	defaultListLimit = 10
-	maxListLimit     = 100
-)
-
-func (u *UserUsecase) ListUsers(ctx context.Context, limit, offset int) ([]*domain.User, error) {
-	if limit <= 0 {
-		limit = defaultListLimit
+// ListUsers returns a page of users and the total matching filter, newest
+// first unless sort says otherwise. When page.After is set the offset is
+// ignored.
+func (u *UserUsecase) ListUsers(
+	ctx context.Context,
+	filter domain.UserFilter,
+	sort domain.UserSort,
+	page domain.Page,
+) (*domain.UserPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if sort == "" {
+		sort = domain.UserSortNewest
+	}
+	if !sort.IsValid() {
+		return nil, fmt.Errorf("%w: unknown sort %q", domain.ErrInvalidUserFilter, sort)
+	}
+	// A cursor only continues the ordering it was issued for.
+	if page.After != nil && domain.UserSort(page.After.Sort) != sort {
+		return nil, domain.ErrInvalidCursor
 	}
-	if limit > maxListLimit {
-		limit = maxListLimit
+
+	// Fetch one extra row to learn whether there is a next page.
+	page, limit := pageWithLookahead(page)
+	users, err := u.repo.List(ctx, filter, sort, page)
+	if err != nil {
+		return nil, err
 	}
-	if offset < 0 {
-		offset = 0
+	total, err := u.repo.Count(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	return u.repo.List(ctx, limit, offset)
+	result := &domain.UserPage{Users: users, Total: total}
+	if len(users) > limit {
+		result.Users = users[:limit]
+		result.NextCursor = sort.CursorAfter(result.Users[limit-1]).Encode()
+	}
+	result.Count = len(result.Users)
+	return result, nil
 }
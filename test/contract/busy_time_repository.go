@@ -0,0 +1,138 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// RunBusyTimeRepositoryContract checks a BusyTimeRepository.
+func RunBusyTimeRepositoryContract(t *testing.T, factory Factory) {
+	t.Run("Sources", func(t *testing.T) { testBusySources(t, factory(t)) })
+	t.Run("Blocks", func(t *testing.T) { testBusyBlocks(t, factory(t)) })
+}
+
+func testBusySources(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.BusyTimes
+	alice := createUser(t, repos.Users, "Alice", "alice@example.com", baseTime())
+	bob := createUser(t, repos.Users, "Bob", "bob@example.com", baseTime())
+
+	upload := createBusySource(t, repo, alice.ID, domain.BusySourceUpload, "")
+	subscription := createBusySource(t, repo, alice.ID, domain.BusySourceURL, "https://example.com/a.ics")
+	createBusySource(t, repo, bob.ID, domain.BusySourceURL, "https://example.com/b.ics")
+
+	got, err := repo.GetSource(ctx, subscription.ID)
+	if err != nil || got.URL != "https://example.com/a.ics" || got.LastSyncedAt != nil {
+		t.Errorf("Expected the subscription, got %+v (%v)", got, err)
+	}
+	if _, err = repo.GetSource(ctx, 999); !errors.Is(err, domain.ErrBusySourceNotFound) {
+		t.Errorf("Expected ErrBusySourceNotFound, got %v", err)
+	}
+
+	sources, err := repo.ListSources(ctx, alice.ID)
+	if err != nil || len(sources) != 2 || sources[0].ID != upload.ID {
+		t.Errorf("Expected Alice's 2 sources by ID, got %d (%v)", len(sources), err)
+	}
+	sources, err = repo.ListURLSources(ctx)
+	if err != nil || len(sources) != 2 || sources[0].ID != subscription.ID {
+		t.Errorf("Expected the 2 URL sources by ID, got %d (%v)", len(sources), err)
+	}
+
+	syncedAt := baseTime().Add(time.Hour)
+	subscription.LastSyncedAt = &syncedAt
+	subscription.LastError = "timeout"
+	if err = repo.UpdateSourceSync(ctx, subscription); err != nil {
+		t.Fatalf("Failed to update sync: %v", err)
+	}
+	got, err = repo.GetSource(ctx, subscription.ID)
+	if err != nil || got.LastSyncedAt == nil || !got.LastSyncedAt.Equal(syncedAt) || got.LastError != "timeout" {
+		t.Errorf("Expected the sync to be recorded, got %+v (%v)", got, err)
+	}
+	if err = repo.UpdateSourceSync(ctx, &domain.BusySource{ID: 999}); !errors.Is(err, domain.ErrBusySourceNotFound) {
+		t.Errorf("Expected ErrBusySourceNotFound updating a missing source, got %v", err)
+	}
+
+	if err = repo.DeleteSource(ctx, upload.ID); err != nil {
+		t.Fatalf("Failed to delete source: %v", err)
+	}
+	if err = repo.DeleteSource(ctx, upload.ID); !errors.Is(err, domain.ErrBusySourceNotFound) {
+		t.Errorf("Expected ErrBusySourceNotFound deleting twice, got %v", err)
+	}
+
+	if err = repos.Users.Delete(ctx, bob.ID); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	if sources, err = repo.ListSources(ctx, bob.ID); err != nil || len(sources) != 0 {
+		t.Errorf("Expected Bob's sources deleted with him, got %d (%v)", len(sources), err)
+	}
+}
+
+func testBusyBlocks(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	repo := repos.BusyTimes
+	user := createUser(t, repos.Users, "Alice", "alice@example.com", baseTime())
+	first := createBusySource(t, repo, user.ID, domain.BusySourceUpload, "")
+	second := createBusySource(t, repo, user.ID, domain.BusySourceUpload, "")
+
+	day := baseTime()
+	replaceBusyBlocks(t, repo, first.ID, user.ID, day.Add(2*time.Hour), day.Add(-time.Hour))
+	replaceBusyBlocks(t, repo, second.ID, user.ID, day.Add(time.Hour))
+
+	// [-1h, 0h), [1h, 2h), [2h, 3h): the first ends where the range begins.
+	blocks, err := repo.ListBlocks(ctx, user.ID, day, day.Add(24*time.Hour))
+	if err != nil || len(blocks) != 2 {
+		t.Fatalf("Expected 2 overlapping blocks, got %d (%v)", len(blocks), err)
+	}
+	if !blocks[0].Start.Equal(day.Add(time.Hour)) || blocks[0].SourceID != second.ID || blocks[1].SourceID != first.ID {
+		t.Errorf("Expected blocks by start time, got %+v", blocks)
+	}
+
+	replaceBusyBlocks(t, repo, first.ID, user.ID)
+	if blocks, err = repo.ListBlocks(ctx, user.ID, day.Add(-time.Hour), day.Add(24*time.Hour)); err != nil ||
+		len(blocks) != 1 {
+		t.Errorf("Expected only the second source's block after replacing, got %d (%v)", len(blocks), err)
+	}
+
+	if err = repo.DeleteSource(ctx, second.ID); err != nil {
+		t.Fatalf("Failed to delete source: %v", err)
+	}
+	if blocks, err = repo.ListBlocks(ctx, user.ID, day.Add(-time.Hour), day.Add(24*time.Hour)); err != nil ||
+		len(blocks) != 0 {
+		t.Errorf("Expected blocks deleted with their source, got %d (%v)", len(blocks), err)
+	}
+}
+
+func createBusySource(
+	t *testing.T,
+	repo domain.BusyTimeRepository,
+	userID int64,
+	kind domain.BusySourceKind,
+	url string,
+) *domain.BusySource {
+	t.Helper()
+
+	source, err := repo.CreateSource(context.Background(), &domain.BusySource{
+		UserID: userID, Kind: kind, URL: url, CreatedAt: baseTime(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create source: %v", err)
+	}
+	return source
+}
+
+// replaceBusyBlocks gives the source an hour-long block at each start.
+func replaceBusyBlocks(t *testing.T, repo domain.BusyTimeRepository, sourceID, userID int64, starts ...time.Time) {
+	t.Helper()
+
+	blocks := make([]domain.BusyBlock, len(starts))
+	for i, start := range starts {
+		blocks[i] = domain.BusyBlock{SourceID: sourceID, UserID: userID, Start: start, End: start.Add(time.Hour)}
+	}
+	if err := repo.ReplaceBlocks(context.Background(), sourceID, blocks); err != nil {
+		t.Fatalf("Failed to replace blocks: %v", err)
+	}
+}
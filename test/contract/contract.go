@@ -0,0 +1,54 @@
+// Package contract holds the behaviour every repository implementation must
+// share, as test suites each backend runs against itself.
+package contract
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// Repositories is one backend's set of repositories over a single database.
+type Repositories struct {
+	Users              domain.UserRepository
+	LineBindingCodes   domain.LineBindingCodeRepository
+	Notifications      domain.NotificationRepository
+	InAppNotifications domain.InAppNotificationRepository
+	BusyTimes          domain.BusyTimeRepository
+	Transactor         domain.Transactor
+}
+
+// Factory returns repositories over a new, empty database.
+type Factory func(t *testing.T) Repositories
+
+// RunAll runs every repository contract against the backend.
+func RunAll(t *testing.T, factory Factory) {
+	t.Run("UserRepository", func(t *testing.T) {
+		RunUserRepositoryContract(t, func(t *testing.T) domain.UserRepository { return factory(t).Users })
+	})
+	t.Run("LineBindingCodeRepository", func(t *testing.T) { RunLineBindingCodeRepositoryContract(t, factory) })
+	t.Run("NotificationRepository", func(t *testing.T) { RunNotificationRepositoryContract(t, factory) })
+	t.Run("InAppNotificationRepository", func(t *testing.T) { RunInAppNotificationRepositoryContract(t, factory) })
+	t.Run("BusyTimeRepository", func(t *testing.T) { RunBusyTimeRepositoryContract(t, factory) })
+	t.Run("Transactor", func(t *testing.T) { RunTransactorContract(t, factory) })
+}
+
+// baseTime is a fixed time with whole seconds, which every backend stores
+// exactly.
+func baseTime() time.Time {
+	return time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+}
+
+func createUser(t *testing.T, repo domain.UserRepository, name, email string, createdAt time.Time) *domain.User {
+	t.Helper()
+
+	user, err := repo.Create(context.Background(), &domain.User{
+		Name: name, Email: email, CreatedAt: createdAt, UpdatedAt: createdAt,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create user %s: %v", name, err)
+	}
+	return user
+}
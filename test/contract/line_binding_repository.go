@@ -0,0 +1,76 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// RunLineBindingCodeRepositoryContract checks a LineBindingCodeRepository.
+func RunLineBindingCodeRepositoryContract(t *testing.T, factory Factory) {
+	t.Run("SaveAndConsume", func(t *testing.T) {
+		ctx := context.Background()
+		repos := factory(t)
+		user := createUser(t, repos.Users, "Alice", "alice@example.com", baseTime())
+
+		code := &domain.LineBindingCode{
+			Code: "K7PX2MQA", UserID: user.ID, ExpiresAt: baseTime().Add(domain.LineBindingCodeTTL), CreatedAt: baseTime(),
+		}
+		if err := repos.LineBindingCodes.Save(ctx, code); err != nil {
+			t.Fatalf("Failed to save code: %v", err)
+		}
+
+		consumed, err := repos.LineBindingCodes.Consume(ctx, "K7PX2MQA")
+		if err != nil || consumed.UserID != user.ID || !consumed.ExpiresAt.Equal(code.ExpiresAt) {
+			t.Fatalf("Expected the saved code, got %+v (%v)", consumed, err)
+		}
+		if _, err = repos.LineBindingCodes.Consume(ctx, "K7PX2MQA"); !errors.Is(err, domain.ErrInvalidBindingCode) {
+			t.Errorf("Expected ErrInvalidBindingCode consuming a code twice, got %v", err)
+		}
+	})
+
+	t.Run("SaveReplacesUsersCode", func(t *testing.T) {
+		ctx := context.Background()
+		repos := factory(t)
+		user := createUser(t, repos.Users, "Alice", "alice@example.com", baseTime())
+
+		for _, code := range []string{"FIRST111", "SECOND22"} {
+			err := repos.LineBindingCodes.Save(ctx, &domain.LineBindingCode{
+				Code: code, UserID: user.ID, ExpiresAt: baseTime().Add(time.Minute), CreatedAt: baseTime(),
+			})
+			if err != nil {
+				t.Fatalf("Failed to save code %s: %v", code, err)
+			}
+		}
+
+		if _, err := repos.LineBindingCodes.Consume(ctx, "FIRST111"); !errors.Is(err, domain.ErrInvalidBindingCode) {
+			t.Errorf("Expected the first code to be replaced, got %v", err)
+		}
+		if _, err := repos.LineBindingCodes.Consume(ctx, "SECOND22"); err != nil {
+			t.Errorf("Expected the second code to be valid, got %v", err)
+		}
+	})
+
+	t.Run("DeletedWithUser", func(t *testing.T) {
+		ctx := context.Background()
+		repos := factory(t)
+		user := createUser(t, repos.Users, "Alice", "alice@example.com", baseTime())
+
+		err := repos.LineBindingCodes.Save(ctx, &domain.LineBindingCode{
+			Code: "K7PX2MQA", UserID: user.ID, ExpiresAt: baseTime().Add(time.Minute), CreatedAt: baseTime(),
+		})
+		if err != nil {
+			t.Fatalf("Failed to save code: %v", err)
+		}
+		if err = repos.Users.Delete(ctx, user.ID); err != nil {
+			t.Fatalf("Failed to delete user: %v", err)
+		}
+
+		if _, err = repos.LineBindingCodes.Consume(ctx, "K7PX2MQA"); !errors.Is(err, domain.ErrInvalidBindingCode) {
+			t.Errorf("Expected the code to be deleted with the user, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,245 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// RunNotificationRepositoryContract checks a NotificationRepository.
+func RunNotificationRepositoryContract(t *testing.T, factory Factory) {
+	t.Run("EnqueueAndGet", func(t *testing.T) { testNotificationEnqueueAndGet(t, factory(t)) })
+	t.Run("ClaimDue", func(t *testing.T) { testNotificationClaimDue(t, factory(t)) })
+	t.Run("UpdateDelivery", func(t *testing.T) { testNotificationUpdateDelivery(t, factory(t)) })
+	t.Run("UpdateDeliveryChecksLease", func(t *testing.T) { testNotificationUpdateDeliveryLease(t, factory(t)) })
+}
+
+func testNotificationEnqueueAndGet(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	user := createUser(t, repos.Users, "Alice", "alice@example.com", baseTime())
+
+	notifications := enqueueNotifications(t, repos, user.ID, baseTime(), baseTime())
+	if notifications[0].ID == 0 || notifications[0].ID == notifications[1].ID {
+		t.Fatalf("Expected distinct IDs, got %d and %d", notifications[0].ID, notifications[1].ID)
+	}
+
+	got, err := repos.Notifications.GetByID(ctx, notifications[0].ID)
+	if err != nil || got.UserID != user.ID || got.Status != domain.NotificationStatusPending ||
+		got.Channel != domain.NotificationChannelEmail || got.SentAt != nil {
+		t.Errorf("Expected the pending notification, got %+v (%v)", got, err)
+	}
+	if _, err = repos.Notifications.GetByID(ctx, 999); !errors.Is(err, domain.ErrNotificationNotFound) {
+		t.Errorf("Expected ErrNotificationNotFound, got %v", err)
+	}
+}
+
+func testNotificationClaimDue(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	user := createUser(t, repos.Users, "Alice", "alice@example.com", baseTime())
+	now := baseTime().Add(time.Hour)
+
+	// Enqueued out of order; the last one isn't due yet.
+	notifications := enqueueNotifications(t, repos, user.ID,
+		now.Add(-time.Minute), now.Add(-time.Hour), now.Add(-2*time.Minute), now.Add(time.Minute))
+
+	claimed, err := repos.Notifications.ClaimDue(ctx, now, 2, now.Add(time.Minute))
+	if err != nil || len(claimed) != 2 || claimed[0].ID != notifications[1].ID || claimed[1].ID != notifications[2].ID {
+		t.Fatalf("Expected the two most overdue notifications, got %v (%v)", notificationIDs(claimed), err)
+	}
+
+	claimed, err = repos.Notifications.ClaimDue(ctx, now, 10, now.Add(time.Minute))
+	if err != nil || len(claimed) != 1 || claimed[0].ID != notifications[0].ID {
+		t.Fatalf("Expected leased notifications to be skipped, got %v (%v)", notificationIDs(claimed), err)
+	}
+
+	// Leases that ran out are claimed again.
+	later := now.Add(time.Minute)
+	claimed, err = repos.Notifications.ClaimDue(ctx, later, 10, later.Add(time.Minute))
+	if err != nil || len(claimed) != 4 {
+		t.Errorf("Expected all notifications once leases expire, got %v (%v)", notificationIDs(claimed), err)
+	}
+
+	// Notifications due at the same time are claimed in ID order.
+	tied := enqueueNotifications(t, repos, user.ID, now, now, now)
+	latest := later.Add(time.Minute)
+	claimed, err = repos.Notifications.ClaimDue(ctx, latest, 10, latest.Add(time.Minute))
+	if err != nil || len(claimed) != 7 || !slices.Equal(notificationIDs(claimed[3:6]), notificationIDs(tied)) {
+		t.Errorf("Expected the tied notifications in ID order, got %v (%v)", notificationIDs(claimed), err)
+	}
+}
+
+func testNotificationUpdateDelivery(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	user := createUser(t, repos.Users, "Alice", "alice@example.com", baseTime())
+	notifications := enqueueNotifications(t, repos, user.ID, baseTime(), baseTime(), baseTime())
+
+	sentAt := baseTime().Add(time.Minute)
+	for _, n := range notifications[:2] {
+		n.Status = domain.NotificationStatusSent
+		n.Attempts = 1
+		n.SentAt = &sentAt
+		if err := repos.Notifications.UpdateDelivery(ctx, n); err != nil {
+			t.Fatalf("Failed to update delivery: %v", err)
+		}
+	}
+
+	got, err := repos.Notifications.GetByID(ctx, notifications[0].ID)
+	if err != nil || got.Status != domain.NotificationStatusSent || got.Attempts != 1 ||
+		got.SentAt == nil || !got.SentAt.Equal(sentAt) {
+		t.Errorf("Expected the notification marked sent, got %+v (%v)", got, err)
+	}
+
+	sent, err := repos.Notifications.ListByStatus(ctx, domain.NotificationStatusSent, domain.Page{Limit: 10})
+	if err != nil || len(sent) != 2 || sent[0].ID != notifications[1].ID {
+		t.Errorf("Expected sent notifications newest first, got %v (%v)", notificationIDs(sent), err)
+	}
+	sent, err = repos.Notifications.ListByStatus(ctx, domain.NotificationStatusSent, domain.Page{Limit: 1, Offset: 1})
+	if err != nil || len(sent) != 1 || sent[0].ID != notifications[0].ID {
+		t.Errorf("Expected the second sent notification, got %v (%v)", notificationIDs(sent), err)
+	}
+	after := &domain.Cursor{CreatedAt: notifications[1].CreatedAt, ID: notifications[1].ID}
+	sent, err = repos.Notifications.ListByStatus(ctx, domain.NotificationStatusSent, domain.Page{Limit: 10, After: after})
+	if err != nil || len(sent) != 1 || sent[0].ID != notifications[0].ID {
+		t.Errorf("Expected the notification after the cursor, got %v (%v)", notificationIDs(sent), err)
+	}
+	count, err := repos.Notifications.CountByStatus(ctx, domain.NotificationStatusSent)
+	if err != nil || count != 2 {
+		t.Errorf("Expected 2 sent notifications, got %d (%v)", count, err)
+	}
+
+	missing := &domain.Notification{ID: 999, Status: domain.NotificationStatusSent}
+	if err = repos.Notifications.UpdateDelivery(ctx, missing); !errors.Is(err, domain.ErrNotificationNotFound) {
+		t.Errorf("Expected ErrNotificationNotFound, got %v", err)
+	}
+}
+
+func testNotificationUpdateDeliveryLease(t *testing.T, repos Repositories) {
+	ctx := context.Background()
+	user := createUser(t, repos.Users, "Alice", "alice@example.com", baseTime())
+	enqueueNotifications(t, repos, user.ID, baseTime())
+
+	now := baseTime().Add(time.Hour)
+	first, err := repos.Notifications.ClaimDue(ctx, now, 1, now.Add(time.Minute))
+	if err != nil || len(first) != 1 || first[0].LockedUntil == nil {
+		t.Fatalf("Expected a leased notification, got %+v (%v)", first, err)
+	}
+
+	// The first worker's lease runs out and a second worker claims the row.
+	later := now.Add(2 * time.Minute)
+	second, err := repos.Notifications.ClaimDue(ctx, later, 1, later.Add(time.Minute))
+	if err != nil || len(second) != 1 {
+		t.Fatalf("Expected the notification to be claimed again, got %+v (%v)", second, err)
+	}
+
+	first[0].Status = domain.NotificationStatusSent
+	first[0].Attempts = 1
+	if err = repos.Notifications.UpdateDelivery(ctx, first[0]); !errors.Is(err, domain.ErrNotificationLeaseLost) {
+		t.Fatalf("Expected ErrNotificationLeaseLost, got %v", err)
+	}
+
+	second[0].Attempts = 1
+	second[0].LastError = "temporary failure"
+	if err = repos.Notifications.UpdateDelivery(ctx, second[0]); err != nil {
+		t.Fatalf("Expected the current lease holder to update, got %v", err)
+	}
+	got, err := repos.Notifications.GetByID(ctx, second[0].ID)
+	if err != nil || got.Status != domain.NotificationStatusPending || got.LastError != "temporary failure" ||
+		got.LockedUntil != nil {
+		t.Errorf("Expected the second worker's result with the lease released, got %+v (%v)", got, err)
+	}
+}
+
+// RunInAppNotificationRepositoryContract checks an InAppNotificationRepository.
+func RunInAppNotificationRepositoryContract(t *testing.T, factory Factory) {
+	ctx := context.Background()
+	repos := factory(t)
+	alice := createUser(t, repos.Users, "Alice", "alice@example.com", baseTime())
+	bob := createUser(t, repos.Users, "Bob", "bob@example.com", baseTime())
+
+	// Alice's second and third notifications share a creation time.
+	var created []*domain.InAppNotification
+	for i, userID := range []int64{alice.ID, alice.ID, bob.ID, alice.ID} {
+		n := &domain.InAppNotification{
+			UserID:    userID,
+			Event:     domain.NotificationEventLineBound,
+			Title:     "Title",
+			Body:      "Body",
+			CreatedAt: baseTime().Add(time.Duration(min(i, 1)) * time.Minute),
+		}
+		if err := repos.InAppNotifications.Create(ctx, n); err != nil {
+			t.Fatalf("Failed to create notification: %v", err)
+		}
+		created = append(created, n)
+	}
+
+	inbox, err := repos.InAppNotifications.ListByUser(ctx, alice.ID, domain.Page{Limit: 10})
+	if err != nil || len(inbox) != 3 {
+		t.Fatalf("Expected Alice's 3 notifications, got %d (%v)", len(inbox), err)
+	}
+	// Newest first, then by ID.
+	if inbox[0].ID != created[3].ID || inbox[1].ID != created[1].ID || inbox[2].ID != created[0].ID {
+		t.Errorf("Expected newest first, got %+v", inbox)
+	}
+
+	inbox, err = repos.InAppNotifications.ListByUser(ctx, alice.ID, domain.Page{Limit: 1, Offset: 2})
+	if err != nil || len(inbox) != 1 || inbox[0].ID != created[0].ID {
+		t.Errorf("Expected Alice's oldest notification, got %+v (%v)", inbox, err)
+	}
+
+	// The cursor continues within a creation time, then into older ones.
+	after := &domain.Cursor{CreatedAt: created[1].CreatedAt, ID: created[1].ID}
+	inbox, err = repos.InAppNotifications.ListByUser(ctx, alice.ID, domain.Page{Limit: 10, After: after})
+	if err != nil || len(inbox) != 1 || inbox[0].ID != created[0].ID {
+		t.Errorf("Expected the notification after the cursor, got %+v (%v)", inbox, err)
+	}
+	after = &domain.Cursor{CreatedAt: created[3].CreatedAt, ID: created[3].ID}
+	inbox, err = repos.InAppNotifications.ListByUser(ctx, alice.ID, domain.Page{Limit: 1, After: after})
+	if err != nil || len(inbox) != 1 || inbox[0].ID != created[1].ID {
+		t.Errorf("Expected the tied notification after the cursor, got %+v (%v)", inbox, err)
+	}
+
+	for userID, expected := range map[int64]int{alice.ID: 3, bob.ID: 1, 999: 0} {
+		if count, countErr := repos.InAppNotifications.CountByUser(ctx, userID); countErr != nil || count != expected {
+			t.Errorf("Expected %d notifications for user %d, got %d (%v)", expected, userID, count, countErr)
+		}
+	}
+}
+
+func enqueueNotifications(
+	t *testing.T,
+	repos Repositories,
+	userID int64,
+	nextAttempts ...time.Time,
+) []*domain.Notification {
+	t.Helper()
+
+	notifications := make([]*domain.Notification, len(nextAttempts))
+	for i, nextAttempt := range nextAttempts {
+		notifications[i] = &domain.Notification{
+			UserID:        userID,
+			Event:         domain.NotificationEventLineBound,
+			Channel:       domain.NotificationChannelEmail,
+			Title:         "Title",
+			Body:          "Body",
+			Status:        domain.NotificationStatusPending,
+			NextAttemptAt: nextAttempt,
+			CreatedAt:     baseTime(),
+		}
+	}
+	if err := repos.Notifications.Enqueue(context.Background(), notifications); err != nil {
+		t.Fatalf("Failed to enqueue: %v", err)
+	}
+	return notifications
+}
+
+func notificationIDs(notifications []*domain.Notification) []int64 {
+	ids := make([]int64, len(notifications))
+	for i, n := range notifications {
+		ids[i] = n.ID
+	}
+	return ids
+}
@@ -0,0 +1,62 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// RunTransactorContract checks that a Transactor commits and rolls back the
+// repositories built on the same database.
+func RunTransactorContract(t *testing.T, factory Factory) {
+	errAbort := errors.New("abort")
+
+	t.Run("Commit", func(t *testing.T) {
+		ctx := context.Background()
+		repos := factory(t)
+
+		err := repos.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+			createUserIn(ctx, t, repos.Users, "alice@example.com")
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to commit: %v", err)
+		}
+		if _, err = repos.Users.GetByEmail(ctx, "alice@example.com"); err != nil {
+			t.Errorf("Expected the user to be committed, got %v", err)
+		}
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		ctx := context.Background()
+		repos := factory(t)
+
+		err := repos.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+			createUserIn(ctx, t, repos.Users, "alice@example.com")
+			// Nested calls join the outer transaction.
+			return repos.Transactor.WithinTx(ctx, func(ctx context.Context) error {
+				createUserIn(ctx, t, repos.Users, "bob@example.com")
+				return errAbort
+			})
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("Expected the transaction error, got %v", err)
+		}
+
+		count, err := repos.Users.Count(ctx, domain.UserFilter{})
+		if err != nil || count != 0 {
+			t.Errorf("Expected both users rolled back, got %d (%v)", count, err)
+		}
+	})
+}
+
+func createUserIn(ctx context.Context, t *testing.T, repo domain.UserRepository, email string) {
+	t.Helper()
+
+	_, err := repo.Create(ctx, &domain.User{Name: "User", Email: email, CreatedAt: baseTime(), UpdatedAt: baseTime()})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+}
@@ -0,0 +1,278 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// RunUserRepositoryContract checks a UserRepository. newRepo must return a
+// repository over a new, empty database on each call.
+func RunUserRepositoryContract(t *testing.T, newRepo func(t *testing.T) domain.UserRepository) {
+	t.Run("CreateAndGet", func(t *testing.T) { testUserCreateAndGet(t, newRepo(t)) })
+	t.Run("MissingUser", func(t *testing.T) { testUserMissing(t, newRepo(t)) })
+	t.Run("UniqueEmail", func(t *testing.T) { testUserUniqueEmail(t, newRepo(t)) })
+	t.Run("ListOrder", func(t *testing.T) { testUserListOrder(t, newRepo(t)) })
+	t.Run("ListFilterAndCount", func(t *testing.T) { testUserListFilter(t, newRepo(t)) })
+	t.Run("LineAccount", func(t *testing.T) { testUserLineAccount(t, newRepo(t)) })
+	t.Run("NotificationPreferences", func(t *testing.T) { testUserPreferences(t, newRepo(t)) })
+	t.Run("Delete", func(t *testing.T) { testUserDelete(t, newRepo(t)) })
+}
+
+func testUserCreateAndGet(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	created := createUser(t, repo, "Alice", "alice@example.com", baseTime())
+	if created.ID == 0 {
+		t.Fatal("Expected Create to assign an ID")
+	}
+
+	byID, err := repo.GetByID(ctx, created.ID)
+	if err != nil || byID.Name != "Alice" || byID.Email != "alice@example.com" {
+		t.Fatalf("Expected the created user by ID, got %+v (%v)", byID, err)
+	}
+	if !byID.CreatedAt.Equal(baseTime()) || !byID.UpdatedAt.Equal(baseTime()) {
+		t.Errorf("Expected timestamps %v, got %v and %v", baseTime(), byID.CreatedAt, byID.UpdatedAt)
+	}
+
+	byEmail, err := repo.GetByEmail(ctx, "alice@example.com")
+	if err != nil || byEmail.ID != created.ID {
+		t.Errorf("Expected the created user by email, got %+v (%v)", byEmail, err)
+	}
+
+	second := createUser(t, repo, "Bob", "bob@example.com", baseTime())
+	if second.ID == created.ID {
+		t.Errorf("Expected distinct IDs, got %d twice", second.ID)
+	}
+}
+
+func testUserMissing(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	if _, err := repo.GetByID(ctx, 999); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound from GetByID, got %v", err)
+	}
+	if _, err := repo.GetByEmail(ctx, "nobody@example.com"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound from GetByEmail, got %v", err)
+	}
+	if _, err := repo.GetByLineUserID(ctx, "U404"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound from GetByLineUserID, got %v", err)
+	}
+	missing := &domain.User{ID: 999, Name: "Nobody", Email: "nobody@example.com", UpdatedAt: baseTime()}
+	if _, err := repo.Update(ctx, missing); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound from Update, got %v", err)
+	}
+}
+
+func testUserUniqueEmail(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	createUser(t, repo, "Alice", "alice@example.com", baseTime())
+	bob := createUser(t, repo, "Bob", "bob@example.com", baseTime())
+
+	_, err := repo.Create(ctx, &domain.User{
+		Name: "Other Alice", Email: "alice@example.com", CreatedAt: baseTime(), UpdatedAt: baseTime(),
+	})
+	if !errors.Is(err, domain.ErrUserExists) {
+		t.Errorf("Expected ErrUserExists creating a duplicate email, got %v", err)
+	}
+
+	bob.Email = "alice@example.com"
+	if _, err = repo.Update(ctx, bob); !errors.Is(err, domain.ErrUserExists) {
+		t.Errorf("Expected ErrUserExists updating to a taken email, got %v", err)
+	}
+
+	count, err := repo.Count(ctx, domain.UserFilter{})
+	if err != nil || count != 2 {
+		t.Errorf("Expected the duplicate not to be stored, got %d users (%v)", count, err)
+	}
+}
+
+func testUserListOrder(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	// Carol and Dave share a creation time, so the ID breaks the tie.
+	createUser(t, repo, "Carol", "carol@example.com", baseTime())
+	createUser(t, repo, "Dave", "dave@example.com", baseTime())
+	createUser(t, repo, "alice", "alice@example.com", baseTime().Add(time.Hour))
+	createUser(t, repo, "Bob", "bob@example.com", baseTime().Add(2*time.Hour))
+
+	assertUserNames(t, repo, domain.UserFilter{}, domain.UserSortNewest, "Bob", "alice", "Dave", "Carol")
+	assertUserNames(t, repo, domain.UserFilter{}, domain.UserSortOldest, "Carol", "Dave", "alice", "Bob")
+	// Names sort bytewise, so upper case comes first.
+	assertUserNames(t, repo, domain.UserFilter{}, domain.UserSortName, "Bob", "Carol", "Dave", "alice")
+	assertUserNames(t, repo, domain.UserFilter{}, domain.UserSortNameDesc, "alice", "Dave", "Carol", "Bob")
+
+	for _, sort := range []domain.UserSort{
+		domain.UserSortNewest, domain.UserSortOldest, domain.UserSortName, domain.UserSortNameDesc,
+	} {
+		all, err := repo.List(ctx, domain.UserFilter{}, sort, domain.Page{Limit: 10})
+		if err != nil {
+			t.Fatalf("Failed to list users: %v", err)
+		}
+		var paged []*domain.User
+		p := domain.Page{Limit: 1}
+		for range all {
+			users, listErr := repo.List(ctx, domain.UserFilter{}, sort, p)
+			if listErr != nil || len(users) != 1 {
+				t.Fatalf("Sort %s: expected one user per page, got %d (%v)", sort, len(users), listErr)
+			}
+			paged = append(paged, users[0])
+			after := sort.CursorAfter(users[0])
+			p.After = &after
+		}
+		if userNames(paged) != userNames(all) {
+			t.Errorf("Sort %s: expected cursor pages %s, got %s", sort, userNames(all), userNames(paged))
+		}
+
+		offset, err := repo.List(ctx, domain.UserFilter{}, sort, domain.Page{Limit: 2, Offset: 1})
+		if err != nil || userNames(offset) != userNames(all[1:3]) {
+			t.Errorf("Sort %s: expected offset page %s, got %s (%v)", sort, userNames(all[1:3]), userNames(offset), err)
+		}
+	}
+}
+
+func testUserListFilter(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	createUser(t, repo, "Carol", "carol@example.com", baseTime())
+	createUser(t, repo, "alice", "alice@church.org", baseTime().Add(time.Hour))
+	createUser(t, repo, "Bob", "bob@example.com", baseTime().Add(2*time.Hour))
+	createUser(t, repo, "100%_sure", "sure@example.com", baseTime().Add(3*time.Hour))
+	createUser(t, repo, "Émile", "emile@example.org", baseTime().Add(4*time.Hour))
+
+	assertUserNames(t, repo, domain.UserFilter{Query: "ALICE"}, domain.UserSortOldest, "alice")
+	assertUserNames(t, repo, domain.UserFilter{Query: "church"}, domain.UserSortOldest, "alice")
+	assertUserNames(t, repo, domain.UserFilter{Name: "o"}, domain.UserSortOldest, "Carol", "Bob")
+	// Only ASCII letters fold, as in SQLite's LIKE.
+	assertUserNames(t, repo, domain.UserFilter{Name: "MILE"}, domain.UserSortOldest, "Émile")
+	assertUserNames(t, repo, domain.UserFilter{Name: "émile"}, domain.UserSortOldest)
+	// Wildcards match literally.
+	assertUserNames(t, repo, domain.UserFilter{Name: "%_"}, domain.UserSortOldest, "100%_sure")
+	assertUserNames(t, repo, domain.UserFilter{Name: "_"}, domain.UserSortOldest, "100%_sure")
+	assertUserNames(t, repo, domain.UserFilter{
+		CreatedFrom: baseTime().Add(time.Hour),
+		CreatedTo:   baseTime().Add(3 * time.Hour),
+	}, domain.UserSortOldest, "alice", "Bob")
+
+	count, err := repo.Count(ctx, domain.UserFilter{Query: "example.com"})
+	if err != nil || count != 3 {
+		t.Errorf("Expected 3 users matching example.com, got %d (%v)", count, err)
+	}
+}
+
+func testUserLineAccount(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	alice := createUser(t, repo, "Alice", "alice@example.com", baseTime())
+	bob := createUser(t, repo, "Bob", "bob@example.com", baseTime())
+
+	if err := repo.BindLineAccount(ctx, alice.ID, "U123"); err != nil {
+		t.Fatalf("Failed to bind: %v", err)
+	}
+	bound, err := repo.GetByLineUserID(ctx, "U123")
+	if err != nil || bound.ID != alice.ID || bound.LineUserID != "U123" {
+		t.Fatalf("Expected Alice bound to U123, got %+v (%v)", bound, err)
+	}
+	if err = repo.BindLineAccount(ctx, bob.ID, "U123"); !errors.Is(err, domain.ErrLineAccountBound) {
+		t.Errorf("Expected ErrLineAccountBound binding a taken account, got %v", err)
+	}
+
+	// Binding again replaces the user's account.
+	if err = repo.BindLineAccount(ctx, alice.ID, "U456"); err != nil {
+		t.Fatalf("Failed to rebind: %v", err)
+	}
+	if _, err = repo.GetByLineUserID(ctx, "U123"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected the old account to be released, got %v", err)
+	}
+
+	if err = repo.UnbindLineAccount(ctx, alice.ID); err != nil {
+		t.Fatalf("Failed to unbind: %v", err)
+	}
+	got, err := repo.GetByID(ctx, alice.ID)
+	if err != nil || got.LineUserID != "" {
+		t.Errorf("Expected Alice to be unbound, got %+v (%v)", got, err)
+	}
+}
+
+func testUserPreferences(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	user := createUser(t, repo, "Alice", "alice@example.com", baseTime())
+
+	got, err := repo.GetByID(ctx, user.ID)
+	if err != nil || got.NotificationPreferences.Channels != nil || got.NotificationPreferences.QuietHours != nil {
+		t.Fatalf("Expected empty preferences, got %+v (%v)", got, err)
+	}
+
+	preferences := domain.NotificationPreferences{
+		Channels: map[domain.NotificationEvent]map[domain.NotificationChannel]bool{
+			domain.NotificationEventLineBound: {domain.NotificationChannelEmail: false},
+		},
+		QuietHours: &domain.QuietHours{Start: "22:00", End: "07:00", Timezone: "Asia/Tokyo"},
+	}
+	if err = repo.UpdateNotificationPreferences(ctx, user.ID, preferences); err != nil {
+		t.Fatalf("Failed to update preferences: %v", err)
+	}
+	// The repository must not keep the caller's maps.
+	preferences.Channels[domain.NotificationEventLineBound][domain.NotificationChannelEmail] = true
+
+	got, err = repo.GetByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	enabled, ok := got.NotificationPreferences.Channels[domain.NotificationEventLineBound][domain.NotificationChannelEmail]
+	if !ok || enabled {
+		t.Errorf("Expected email disabled for line_bound, got %+v", got.NotificationPreferences.Channels)
+	}
+	if quiet := got.NotificationPreferences.QuietHours; quiet == nil || quiet.Timezone != "Asia/Tokyo" {
+		t.Errorf("Expected quiet hours in Asia/Tokyo, got %+v", quiet)
+	}
+}
+
+func testUserDelete(t *testing.T, repo domain.UserRepository) {
+	ctx := context.Background()
+	user := createUser(t, repo, "Alice", "alice@example.com", baseTime())
+	if err := repo.BindLineAccount(ctx, user.ID, "U123"); err != nil {
+		t.Fatalf("Failed to bind: %v", err)
+	}
+
+	if err := repo.Delete(ctx, user.ID); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound after delete, got %v", err)
+	}
+	if _, err := repo.GetByLineUserID(ctx, "U123"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected the Line binding to be deleted with the user, got %v", err)
+	}
+	if err := repo.Delete(ctx, user.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound deleting a missing user, got %v", err)
+	}
+
+	// The email is free again.
+	createUser(t, repo, "Alice", "alice@example.com", baseTime())
+}
+
+func assertUserNames(
+	t *testing.T,
+	repo domain.UserRepository,
+	filter domain.UserFilter,
+	sort domain.UserSort,
+	expected ...string,
+) {
+	t.Helper()
+
+	users, err := repo.List(context.Background(), filter, sort, domain.Page{Limit: 10})
+	if err != nil {
+		t.Fatalf("Failed to list users: %v", err)
+	}
+	if got := userNames(users); got != strings.Join(expected, ",") {
+		t.Errorf("Sort %s, filter %+v: expected %v, got %s", sort, filter, expected, got)
+	}
+}
+
+func userNames(users []*domain.User) string {
+	names := make([]string, len(users))
+	for i, user := range users {
+		names[i] = user.Name
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,184 @@
+package domain_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+func calendar(lines ...string) []byte {
+	all := append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, lines...)
+	return []byte(strings.Join(append(all, "END:VCALENDAR"), "\r\n"))
+}
+
+func TestParseBusyBlocks(t *testing.T) {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		t.Fatalf("Failed to load timezone: %v", err)
+	}
+	from := time.Date(2024, 10, 1, 0, 0, 0, 0, taipei)
+	to := time.Date(2024, 11, 1, 0, 0, 0, 0, taipei)
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2024, month, day, hour, 0, 0, 0, taipei)
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected []domain.BusyBlock
+	}{
+		{
+			name:     "UTC event",
+			data:     calendar("BEGIN:VEVENT", "DTSTART:20241005T010000Z", "DTEND:20241005T030000Z", "END:VEVENT"),
+			expected: []domain.BusyBlock{{Start: at(10, 5, 9), End: at(10, 5, 11)}},
+		},
+		{
+			name: "TZID and duration",
+			data: calendar("BEGIN:VEVENT", "DTSTART;TZID=America/New_York:20241005T090000",
+				"DURATION:PT1H30M", "END:VEVENT"),
+			expected: []domain.BusyBlock{{
+				Start: at(10, 5, 21),
+				End:   at(10, 5, 22).Add(30 * time.Minute),
+			}},
+		},
+		{
+			name: "floating all-day event with folded line",
+			data: calendar("BEGIN:VEVENT", "SUMMARY:Family", " trip", "DTSTART;VALUE=DATE:20241010",
+				"DTEND;VALUE=DATE:20241012", "END:VEVENT"),
+			expected: []domain.BusyBlock{{Start: at(10, 10, 0), End: at(10, 12, 0)}},
+		},
+		{
+			name: "free and cancelled events are skipped",
+			data: calendar(
+				"BEGIN:VEVENT", "DTSTART:20241005T010000Z", "DTEND:20241005T030000Z", "TRANSP:TRANSPARENT", "END:VEVENT",
+				"BEGIN:VEVENT", "DTSTART:20241006T010000Z", "DTEND:20241006T030000Z", "STATUS:CANCELLED", "END:VEVENT",
+			),
+		},
+		{
+			name: "weekly BYDAY with EXDATE",
+			data: calendar("BEGIN:VEVENT", "UID:class", "DTSTART;TZID=Asia/Taipei:20241001T190000",
+				"DTEND;TZID=Asia/Taipei:20241001T200000", "RRULE:FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4",
+				"EXDATE;TZID=Asia/Taipei:20241003T190000", "END:VEVENT"),
+			expected: []domain.BusyBlock{
+				{Start: at(10, 1, 19), End: at(10, 1, 20)},
+				{Start: at(10, 8, 19), End: at(10, 8, 20)},
+				{Start: at(10, 10, 19), End: at(10, 10, 20)},
+			},
+		},
+		{
+			name: "daily until with moved instance",
+			data: calendar(
+				"BEGIN:VEVENT", "UID:shift", "DTSTART;TZID=Asia/Taipei:20240929T080000",
+				"DTEND;TZID=Asia/Taipei:20240929T090000", "RRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20241003T000000Z",
+				"END:VEVENT",
+				"BEGIN:VEVENT", "UID:shift", "RECURRENCE-ID;TZID=Asia/Taipei:20241001T080000",
+				"DTSTART;TZID=Asia/Taipei:20241001T140000", "DTEND;TZID=Asia/Taipei:20241001T150000",
+				"END:VEVENT",
+			),
+			expected: []domain.BusyBlock{
+				{Start: at(10, 1, 14), End: at(10, 1, 15)},
+				{Start: at(10, 3, 8), End: at(10, 3, 9)},
+			},
+		},
+		{
+			name: "alarm properties don't apply to the event",
+			data: calendar("BEGIN:VEVENT", "DTSTART:20241005T010000Z", "DURATION:PT2H",
+				"BEGIN:VALARM", "ACTION:DISPLAY", "TRIGGER:-PT15M", "DURATION:PT5M", "REPEAT:2",
+				"DESCRIPTION:Reminder", "END:VALARM", "END:VEVENT"),
+			expected: []domain.BusyBlock{{Start: at(10, 5, 9), End: at(10, 5, 11)}},
+		},
+		{
+			name: "daily until a date includes that day",
+			data: calendar("BEGIN:VEVENT", "DTSTART;TZID=Asia/Taipei:20241007T190000",
+				"DTEND;TZID=Asia/Taipei:20241007T200000", "RRULE:FREQ=DAILY;UNTIL=20241009", "END:VEVENT"),
+			expected: []domain.BusyBlock{
+				{Start: at(10, 7, 19), End: at(10, 7, 20)},
+				{Start: at(10, 8, 19), End: at(10, 8, 20)},
+				{Start: at(10, 9, 19), End: at(10, 9, 20)},
+			},
+		},
+		{
+			name: "monthly on the 31st skips shorter months",
+			data: calendar("BEGIN:VEVENT", "DTSTART;TZID=Asia/Taipei:20240731T100000",
+				"DTEND;TZID=Asia/Taipei:20240731T110000", "RRULE:FREQ=MONTHLY;COUNT=3", "END:VEVENT"),
+			expected: []domain.BusyBlock{{Start: at(10, 31, 10), End: at(10, 31, 11)}},
+		},
+		{
+			name: "monthly BYMONTHDAY from the end of the month",
+			data: calendar("BEGIN:VEVENT", "DTSTART;TZID=Asia/Taipei:20240115T100000",
+				"DTEND;TZID=Asia/Taipei:20240115T110000", "RRULE:FREQ=MONTHLY;BYMONTHDAY=1,-1", "END:VEVENT"),
+			expected: []domain.BusyBlock{
+				{Start: at(10, 1, 10), End: at(10, 1, 11)},
+				{Start: at(10, 31, 10), End: at(10, 31, 11)},
+			},
+		},
+		{
+			name: "old weekly rule counts occurrences before the range",
+			data: calendar("BEGIN:VEVENT", "DTSTART;TZID=Asia/Taipei:20000104T190000",
+				"DTEND;TZID=Asia/Taipei:20000104T200000", "RRULE:FREQ=WEEKLY;BYDAY=TU,TH;COUNT=2584", "END:VEVENT"),
+			expected: []domain.BusyBlock{
+				{Start: at(10, 1, 19), End: at(10, 1, 20)},
+				{Start: at(10, 3, 19), End: at(10, 3, 20)},
+			},
+		},
+		{
+			name: "old yearly rule",
+			data: calendar("BEGIN:VEVENT", "DTSTART;VALUE=DATE:19040229", "DTEND;VALUE=DATE:19040301",
+				"RRULE:FREQ=YEARLY", "END:VEVENT",
+				"BEGIN:VEVENT", "DTSTART;VALUE=DATE:19001005", "DTEND;VALUE=DATE:19001006",
+				"RRULE:FREQ=YEARLY", "END:VEVENT"),
+			expected: []domain.BusyBlock{{Start: at(10, 5, 0), End: at(10, 6, 0)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, parseErr := domain.ParseBusyBlocks(tt.data, from, to)
+			if parseErr != nil {
+				t.Fatalf("Unexpected error: %v", parseErr)
+			}
+			if len(blocks) != len(tt.expected) {
+				t.Fatalf("Expected %d blocks, got %+v", len(tt.expected), blocks)
+			}
+			for i, block := range blocks {
+				if !block.Start.Equal(tt.expected[i].Start) || !block.End.Equal(tt.expected[i].End) {
+					t.Errorf("Block %d: expected %v – %v, got %v – %v",
+						i, tt.expected[i].Start, tt.expected[i].End, block.Start, block.End)
+				}
+			}
+		})
+	}
+}
+
+func TestParseBusyBlocks_InvalidCalendar(t *testing.T) {
+	from := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	inputs := [][]byte{
+		[]byte("hello"),
+		calendar("BEGIN:VEVENT", "DTEND:20241005T030000Z", "END:VEVENT"),
+		calendar("BEGIN:VEVENT", "DTSTART:tomorrow", "END:VEVENT"),
+	}
+	for _, data := range inputs {
+		if _, err := domain.ParseBusyBlocks(data, from, to); !errors.Is(err, domain.ErrInvalidCalendar) {
+			t.Errorf("Expected ErrInvalidCalendar for %q, got %v", data, err)
+		}
+	}
+}
+
+func TestParseBusyBlocks_TooManyBusyTimes(t *testing.T) {
+	from := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	lines := make([]string, 0, 2*domain.MaxBusyBlocksPerCalendar)
+	for range domain.MaxBusyBlocksPerCalendar/2 + 1 {
+		lines = append(lines, "BEGIN:VEVENT", "DTSTART:20241001T000000Z", "DTEND:20241001T000100Z",
+			"RRULE:FREQ=DAILY;COUNT=2", "END:VEVENT")
+	}
+	if _, err := domain.ParseBusyBlocks(calendar(lines...), from, to); !errors.Is(err, domain.ErrInvalidCalendar) {
+		t.Errorf("Expected ErrInvalidCalendar, got %v", err)
+	}
+}
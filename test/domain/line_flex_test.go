@@ -0,0 +1,99 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"ministry-scheduler/internal/domain"
+)
+
+func TestFlexCardBuilder_Message(t *testing.T) {
+	msg := domain.NewFlexCard("我的服事").
+		Subtitle("10/20 主日").
+		Field("崗位", "音控").
+		Text("請提早 30 分鐘到場").
+		PostbackButton("確認出席", "action=confirm").
+		Message("我的服事")
+
+	if msg.Type != domain.LineMessageTypeFlex {
+		t.Errorf("Expected type %s, got %s", domain.LineMessageTypeFlex, msg.Type)
+	}
+	if msg.AltText != "我的服事" {
+		t.Errorf("Expected altText 我的服事, got %s", msg.AltText)
+	}
+
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+
+	var decoded struct {
+		Type     string `json:"type"`
+		AltText  string `json:"altText"`
+		Contents struct {
+			Type   string `json:"type"`
+			Header struct {
+				Contents []struct {
+					Text string `json:"text"`
+				} `json:"contents"`
+			} `json:"header"`
+			Body struct {
+				Contents []struct {
+					Type     string `json:"type"`
+					Text     string `json:"text"`
+					Contents []struct {
+						Text string `json:"text"`
+					} `json:"contents"`
+				} `json:"contents"`
+			} `json:"body"`
+			Footer struct {
+				Contents []struct {
+					Type   string `json:"type"`
+					Action struct {
+						Type  string `json:"type"`
+						Label string `json:"label"`
+						Data  string `json:"data"`
+					} `json:"action"`
+				} `json:"contents"`
+			} `json:"footer"`
+		} `json:"contents"`
+	}
+	if err = json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal message: %v", err)
+	}
+
+	if decoded.Contents.Type != "bubble" {
+		t.Errorf("Expected bubble contents, got %s", decoded.Contents.Type)
+	}
+	if header := decoded.Contents.Header.Contents; len(header) != 2 || header[0].Text != "我的服事" ||
+		header[1].Text != "10/20 主日" {
+		t.Errorf("Unexpected header %+v", header)
+	}
+
+	body := decoded.Contents.Body.Contents
+	if len(body) != 2 {
+		t.Fatalf("Expected 2 body components, got %d", len(body))
+	}
+	if body[0].Type != "box" || len(body[0].Contents) != 2 || body[0].Contents[1].Text != "音控" {
+		t.Errorf("Unexpected field row %+v", body[0])
+	}
+	if body[1].Type != "text" || body[1].Text != "請提早 30 分鐘到場" {
+		t.Errorf("Unexpected text component %+v", body[1])
+	}
+
+	footer := decoded.Contents.Footer.Contents
+	if len(footer) != 1 || footer[0].Action.Type != "postback" || footer[0].Action.Data != "action=confirm" {
+		t.Errorf("Unexpected footer %+v", footer)
+	}
+}
+
+func TestFlexCardBuilder_OmitsEmptySections(t *testing.T) {
+	bubble := domain.NewFlexCard("公告").Bubble()
+
+	if bubble.Header == nil {
+		t.Fatal("Expected header to be set")
+	}
+	if bubble.Body != nil || bubble.Footer != nil {
+		t.Errorf("Expected empty body and footer to be omitted, got %+v", bubble)
+	}
+}
@@ -0,0 +1,201 @@
+package domain_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+func TestNotificationBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 3, want: 2 * time.Minute},
+		{attempts: 20, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := domain.NotificationBackoff(tt.attempts); got != tt.want {
+			t.Errorf("NotificationBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestNotificationEvent_DefaultChannels(t *testing.T) {
+	channels := domain.NotificationEventLineBound.DefaultChannels()
+	if slices.Contains(channels, domain.NotificationChannelLine) {
+		t.Errorf("Expected line_bound not to be sent over Line, got %v", channels)
+	}
+	if !slices.Contains(channels, domain.NotificationChannelInApp) {
+		t.Errorf("Expected line_bound to be sent in-app, got %v", channels)
+	}
+}
+
+func TestNotificationPreferences_ChannelsFor(t *testing.T) {
+	preferences := domain.NotificationPreferences{
+		Channels: map[domain.NotificationEvent]map[domain.NotificationChannel]bool{
+			domain.NotificationEventLineBound: {
+				domain.NotificationChannelEmail: false,
+				domain.NotificationChannelLine:  true,
+			},
+		},
+	}
+
+	got := preferences.ChannelsFor(domain.NotificationEventLineBound)
+	want := []domain.NotificationChannel{domain.NotificationChannelLine, domain.NotificationChannelInApp}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Events without overrides keep their defaults.
+	got = preferences.ChannelsFor(domain.NotificationEventLineUnbound)
+	if !slices.Equal(got, domain.NotificationEventLineUnbound.DefaultChannels()) {
+		t.Errorf("Expected default channels, got %v", got)
+	}
+}
+
+func TestNotificationPreferences_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences domain.NotificationPreferences
+		wantErr     bool
+	}{
+		{name: "empty", preferences: domain.NotificationPreferences{}},
+		{
+			name: "overnight quiet hours",
+			preferences: domain.NotificationPreferences{
+				QuietHours: &domain.QuietHours{Start: "22:00", End: "07:00", Timezone: "Asia/Taipei"},
+			},
+		},
+		{
+			name: "unknown channel",
+			preferences: domain.NotificationPreferences{
+				Channels: map[domain.NotificationEvent]map[domain.NotificationChannel]bool{
+					domain.NotificationEventLineBound: {"sms": true},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown event",
+			preferences: domain.NotificationPreferences{
+				Channels: map[domain.NotificationEvent]map[domain.NotificationChannel]bool{"birthday": {}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "bad clock",
+			preferences: domain.NotificationPreferences{
+				QuietHours: &domain.QuietHours{Start: "25:00", End: "07:00", Timezone: "Asia/Taipei"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown timezone",
+			preferences: domain.NotificationPreferences{
+				QuietHours: &domain.QuietHours{Start: "22:00", End: "07:00", Timezone: "Mars/Olympus"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty window",
+			preferences: domain.NotificationPreferences{
+				QuietHours: &domain.QuietHours{Start: "07:00", End: "07:00", Timezone: "Asia/Taipei"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.preferences.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, domain.ErrInvalidNotificationPreferences) {
+				t.Errorf("Expected ErrInvalidNotificationPreferences, got %v", err)
+			}
+		})
+	}
+}
+
+func TestQuietHours_Until(t *testing.T) {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		t.Fatalf("Failed to load timezone: %v", err)
+	}
+	overnight := &domain.QuietHours{Start: "22:00", End: "07:00", Timezone: "Asia/Taipei"}
+	daytime := &domain.QuietHours{Start: "13:00", End: "14:30", Timezone: "Asia/Taipei"}
+
+	tests := []struct {
+		name      string
+		quiet     *domain.QuietHours
+		now       time.Time
+		wantQuiet bool
+		want      time.Time
+	}{
+		{
+			name:      "before midnight",
+			quiet:     overnight,
+			now:       time.Date(2024, 1, 15, 23, 30, 0, 0, taipei),
+			wantQuiet: true,
+			want:      time.Date(2024, 1, 16, 7, 0, 0, 0, taipei),
+		},
+		{
+			name:      "after midnight",
+			quiet:     overnight,
+			now:       time.Date(2024, 1, 16, 1, 0, 0, 0, taipei),
+			wantQuiet: true,
+			want:      time.Date(2024, 1, 16, 7, 0, 0, 0, taipei),
+		},
+		{name: "at end", quiet: overnight, now: time.Date(2024, 1, 16, 7, 0, 0, 0, taipei)},
+		{name: "daytime outside", quiet: daytime, now: time.Date(2024, 1, 16, 12, 59, 0, 0, taipei)},
+		{
+			name:      "daytime inside",
+			quiet:     daytime,
+			now:       time.Date(2024, 1, 16, 14, 0, 0, 0, taipei),
+			wantQuiet: true,
+			want:      time.Date(2024, 1, 16, 14, 30, 0, 0, taipei),
+		},
+		{
+			// 16:00 UTC is 00:00 the next day in Taipei.
+			name:      "other timezone",
+			quiet:     overnight,
+			now:       time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+			wantQuiet: true,
+			want:      time.Date(2024, 1, 16, 7, 0, 0, 0, taipei),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, quiet := tt.quiet.Until(tt.now)
+			if quiet != tt.wantQuiet {
+				t.Fatalf("Expected quiet=%v, got %v", tt.wantQuiet, quiet)
+			}
+			if quiet && !got.Equal(tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNotificationPreferences_QuietUntilSkipsInApp(t *testing.T) {
+	preferences := domain.NotificationPreferences{
+		QuietHours: &domain.QuietHours{Start: "00:00", End: "23:59", Timezone: "UTC"},
+	}
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	if _, quiet := preferences.QuietUntil(domain.NotificationChannelInApp, now); quiet {
+		t.Error("Expected in-app notifications to ignore quiet hours")
+	}
+	if _, quiet := preferences.QuietUntil(domain.NotificationChannelLine, now); !quiet {
+		t.Error("Expected Line notifications to respect quiet hours")
+	}
+}
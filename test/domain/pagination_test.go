@@ -0,0 +1,27 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := domain.Cursor{CreatedAt: time.Date(2024, 10, 1, 8, 30, 0, 123456789, time.UTC), ID: 42}
+
+	decoded, err := domain.DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID {
+		t.Errorf("Expected %+v, got %+v", cursor, decoded)
+	}
+
+	for _, value := range []string{"", "not a cursor!", "e30"} {
+		if _, decodeErr := domain.DecodeCursor(value); !errors.Is(decodeErr, domain.ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor for %q, got %v", value, decodeErr)
+		}
+	}
+}
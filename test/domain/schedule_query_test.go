@@ -0,0 +1,67 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+func TestParseScheduleQuery(t *testing.T) {
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		t.Fatalf("Failed to load timezone: %v", err)
+	}
+	// Wednesday.
+	now := time.Date(2024, 10, 16, 10, 0, 0, 0, taipei)
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, taipei)
+	}
+
+	tests := []struct {
+		text   string
+		intent domain.ScheduleIntent
+		from   time.Time
+		to     time.Time
+	}{
+		{"我下週有服事嗎？", domain.ScheduleIntentMine, day(2024, 10, 21), day(2024, 10, 28)},
+		{"幫我看一下 10 月的安排", domain.ScheduleIntentMine, day(2024, 10, 1), day(2024, 11, 1)},
+		{"這週誰跟我一起服事？", domain.ScheduleIntentCoServers, day(2024, 10, 14), day(2024, 10, 21)},
+		{"明天誰服事", domain.ScheduleIntentTeam, day(2024, 10, 17), day(2024, 10, 18)},
+		{"下週日輪到我嗎", domain.ScheduleIntentMine, day(2024, 10, 27), day(2024, 10, 28)},
+		{"下下禮拜的安排", domain.ScheduleIntentMine, day(2024, 10, 28), day(2024, 11, 4)},
+		{"下個月大家的排班", domain.ScheduleIntentTeam, day(2024, 11, 1), day(2024, 12, 1)},
+		{"１０／２０的服事", domain.ScheduleIntentMine, day(2024, 10, 20), day(2024, 10, 21)},
+		{"10月25日我有服事嗎", domain.ScheduleIntentMine, day(2024, 10, 25), day(2024, 10, 26)},
+		// Months and days that have passed refer to next year.
+		{"9月的班表", domain.ScheduleIntentMine, day(2025, 9, 1), day(2025, 10, 1)},
+		{"10/5 的服事", domain.ScheduleIntentMine, day(2025, 10, 5), day(2025, 10, 6)},
+		{"我有服事嗎", domain.ScheduleIntentMine, day(2024, 10, 16), day(2024, 11, 13)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			query, parseErr := domain.ParseScheduleQuery(tt.text, now)
+			if parseErr != nil {
+				t.Fatalf("Expected no error, got %v", parseErr)
+			}
+			if query.Intent != tt.intent {
+				t.Errorf("Expected intent %s, got %s", tt.intent, query.Intent)
+			}
+			if !query.From.Equal(tt.from) || !query.To.Equal(tt.to) {
+				t.Errorf("Expected %v – %v, got %v – %v", tt.from, tt.to, query.From, query.To)
+			}
+		})
+	}
+}
+
+func TestParseScheduleQuery_NotUnderstood(t *testing.T) {
+	now := time.Date(2024, 10, 16, 10, 0, 0, 0, time.UTC)
+
+	for _, text := range []string{"你好", "", "2/30 的服事", "13月的安排"} {
+		if _, err := domain.ParseScheduleQuery(text, now); !errors.Is(err, domain.ErrScheduleQueryNotUnderstood) {
+			t.Errorf("Expected ErrScheduleQueryNotUnderstood for %q, got %v", text, err)
+		}
+	}
+}
@@ -2,7 +2,9 @@ package domain_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"ministry-scheduler/internal/domain"
 )
@@ -104,3 +106,39 @@ func TestCreateUserRequest_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestUserFilter_Validate(t *testing.T) {
+	day := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		filter  domain.UserFilter
+		wantErr error
+	}{
+		{name: "empty", filter: domain.UserFilter{}},
+		{name: "open range", filter: domain.UserFilter{Name: "王", CreatedFrom: day}},
+		{name: "range", filter: domain.UserFilter{CreatedFrom: day, CreatedTo: day.AddDate(0, 0, 1)}},
+		{
+			name:    "empty range",
+			filter:  domain.UserFilter{CreatedFrom: day, CreatedTo: day},
+			wantErr: domain.ErrInvalidUserFilter,
+		},
+		{
+			name:    "name too long",
+			filter:  domain.UserFilter{Name: strings.Repeat("a", domain.MaxNameLength+1)},
+			wantErr: domain.ErrInvalidUserFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("UserFilter.Validate() error = %v, wantErr nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("UserFilter.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
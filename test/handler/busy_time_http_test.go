@@ -0,0 +1,193 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+// testCalendar has one busy event tomorrow at 19:00 UTC and one free event.
+func testCalendar() string {
+	day := time.Now().UTC().AddDate(0, 0, 1).Format("20060102")
+	return strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:busy@example.com",
+		"SUMMARY:Dentist",
+		"DTSTART:" + day + "T190000Z",
+		"DTEND:" + day + "T203000Z",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:free@example.com",
+		"DTSTART:" + day + "T080000Z",
+		"DTEND:" + day + "T090000Z",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+}
+
+// loopbackCalendarFetcher fetches from the test servers on 127.0.0.1, which
+// infra.HTTPCalendarFetcher refuses to contact.
+type loopbackCalendarFetcher struct{}
+
+func (loopbackCalendarFetcher) Fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calendar fetch returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func uploadCalendar(mux *http.ServeMux, path, calendar string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(calendar))
+	req.Header.Set("Content-Type", "text/calendar; charset=utf-8")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func listBusyTimes(t *testing.T, mux *http.ServeMux, path string) []domain.BusyBlock {
+	t.Helper()
+
+	rec := serve(mux, http.MethodGet, path, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		BusyTimes []domain.BusyBlock `json:"busy_times"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode busy times: %v", err)
+	}
+	return body.BusyTimes
+}
+
+func TestBusyTimes_UploadAndDelete(t *testing.T) {
+	app := newTestApp(t)
+	serve(app.mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+
+	rec := uploadCalendar(app.mux, "/users/1/busy-sources", testCalendar())
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var source domain.BusySource
+	if err := json.NewDecoder(rec.Body).Decode(&source); err != nil {
+		t.Fatalf("Failed to decode source: %v", err)
+	}
+	if source.Kind != domain.BusySourceUpload {
+		t.Errorf("Expected an upload source, got %q", source.Kind)
+	}
+
+	blocks := listBusyTimes(t, app.mux, "/users/1/busy-times")
+	if len(blocks) != 1 || blocks[0].End.Sub(blocks[0].Start) != 90*time.Minute {
+		t.Fatalf("Expected one 90 minute block, got %+v", blocks)
+	}
+	if strings.Contains(rec.Body.String(), "Dentist") {
+		t.Error("Expected event titles not to be stored")
+	}
+
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	today := time.Now().Format("2006-01-02")
+	if blocks = listBusyTimes(t, app.mux, "/users/1/busy-times?from="+yesterday+"&to="+today); len(blocks) != 0 {
+		t.Errorf("Expected no blocks outside the range, got %+v", blocks)
+	}
+
+	if rec = serve(app.mux, http.MethodDelete, "/users/2/busy-sources/1", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another user's source, got %d", rec.Code)
+	}
+	if rec = serve(app.mux, http.MethodDelete, "/users/1/busy-sources/1", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if blocks = listBusyTimes(t, app.mux, "/users/1/busy-times"); len(blocks) != 0 {
+		t.Errorf("Expected blocks to be deleted with their source, got %+v", blocks)
+	}
+}
+
+func TestBusyTimes_SubscribeToURL(t *testing.T) {
+	app := newTestApp(t)
+	serve(app.mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+
+	calendar := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/calendar")
+		_, _ = w.Write([]byte(testCalendar()))
+	}))
+	t.Cleanup(calendar.Close)
+
+	rec := serve(app.mux, http.MethodPost, "/users/1/busy-sources", `{"url": "`+calendar.URL+`/private.ics"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if strings.Contains(rec.Body.String(), calendar.URL) {
+		t.Error("Expected the subscription URL not to be returned")
+	}
+	if blocks := listBusyTimes(t, app.mux, "/users/1/busy-times"); len(blocks) != 1 {
+		t.Errorf("Expected one block, got %+v", blocks)
+	}
+
+	rec = serve(app.mux, http.MethodGet, "/users/1/busy-sources", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"kind":"url"`) {
+		t.Errorf("Expected the subscription to be listed, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestBusyTimes_SubscribeErrorIsGeneric(t *testing.T) {
+	app := newTestApp(t)
+	serve(app.mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	rec := serve(app.mux, http.MethodPost, "/users/1/busy-sources", `{"url": "`+closed.URL+`/private.ics"}`)
+	if rec.Code != http.StatusBadGateway {
+		t.Fatalf("Expected status 502, got %d: %s", rec.Code, rec.Body.String())
+	}
+	// The network error would tell the caller what listens where.
+	if body := rec.Body.String(); strings.Contains(body, "127.0.0.1") || strings.Contains(body, "refused") {
+		t.Errorf("Expected a generic error, got %q", body)
+	}
+}
+
+func TestBusyTimes_InvalidInput(t *testing.T) {
+	app := newTestApp(t)
+	serve(app.mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+
+	tests := []struct {
+		name     string
+		rec      *httptest.ResponseRecorder
+		expected int
+	}{
+		{"not a calendar", uploadCalendar(app.mux, "/users/1/busy-sources", "hello"), http.StatusBadRequest},
+		{"unsupported scheme", serve(app.mux, http.MethodPost, "/users/1/busy-sources",
+			`{"url": "ftp://example.com/cal.ics"}`), http.StatusBadRequest},
+		{"unknown user", uploadCalendar(app.mux, "/users/42/busy-sources", testCalendar()), http.StatusNotFound},
+		{"bad date", serve(app.mux, http.MethodGet, "/users/1/busy-times?from=tomorrow", ""), http.StatusBadRequest},
+		{"unknown source", serve(app.mux, http.MethodDelete, "/users/1/busy-sources/9", ""), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.rec.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, tt.rec.Code, tt.rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ministry-scheduler/internal/domain"
+)
+
+func serve(mux *http.ServeMux, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func sendLineText(t *testing.T, mux *http.ServeMux, lineUserID, text string) {
+	t.Helper()
+
+	event := map[string]any{
+		"type":       "message",
+		"mode":       "active",
+		"replyToken": "reply-" + lineUserID,
+		"source":     map[string]string{"type": "user", "userId": lineUserID},
+		"message":    map[string]string{"id": "1", "type": "text", "text": text},
+	}
+	body, err := json.Marshal(map[string]any{"events": []any{event}})
+	if err != nil {
+		t.Fatalf("Failed to encode event: %v", err)
+	}
+
+	if rec := postWebhook(mux, body, sign(body)); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+}
+
+func getLineUserID(t *testing.T, mux *http.ServeMux, path string) string {
+	t.Helper()
+
+	rec := serve(mux, http.MethodGet, path, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var user domain.User
+	if err := json.NewDecoder(rec.Body).Decode(&user); err != nil {
+		t.Fatalf("Failed to decode user: %v", err)
+	}
+	return user.LineUserID
+}
+
+func generateBindingCode(t *testing.T, mux *http.ServeMux, path string) (string, string) {
+	t.Helper()
+
+	rec := serve(mux, http.MethodPost, path, "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Code string `json:"code"`
+		Link string `json:"link"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp.Code, resp.Link
+}
+
+func TestLineBinding_BindAndUnbind(t *testing.T) {
+	mux, fake := newTestLineWebhook(t)
+
+	rec := serve(mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", rec.Code)
+	}
+
+	code, link := generateBindingCode(t, mux, "/users/1/line-binding")
+	if !strings.HasPrefix(link, "https://line.me/R/oaMessage/@testbot/?") || !strings.Contains(link, code) {
+		t.Errorf("Unexpected binding link %q", link)
+	}
+
+	sendLineText(t, mux, "U123", "綁定 "+code)
+	if got := getLineUserID(t, mux, "/users/1"); got != "U123" {
+		t.Errorf("Expected line_user_id U123, got %q", got)
+	}
+	if replies := fake.Replies(); len(replies) != 1 || !strings.Contains(replies[0].Messages[0].Text, "John Doe") {
+		t.Errorf("Expected a success reply naming the user, got %+v", replies)
+	}
+
+	if rec = serve(mux, http.MethodDelete, "/users/1/line-binding", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", rec.Code)
+	}
+	if got := getLineUserID(t, mux, "/users/1"); got != "" {
+		t.Errorf("Expected binding to be removed, got %q", got)
+	}
+	if rec = serve(mux, http.MethodDelete, "/users/1/line-binding", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestLineBinding_LineAccountIsUnique(t *testing.T) {
+	mux, _ := newTestLineWebhook(t)
+
+	serve(mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+	serve(mux, http.MethodPost, "/users", `{"name": "Jane Doe", "email": "jane@example.com"}`)
+
+	code, _ := generateBindingCode(t, mux, "/users/1/line-binding")
+	sendLineText(t, mux, "U123", "綁定 "+code)
+
+	code, _ = generateBindingCode(t, mux, "/users/2/line-binding")
+	sendLineText(t, mux, "U123", "綁定 "+code)
+	if got := getLineUserID(t, mux, "/users/2"); got != "" {
+		t.Errorf("Expected second user to stay unbound, got %q", got)
+	}
+
+	// Deleting the first user releases the Line account for rebinding.
+	if rec := serve(mux, http.MethodDelete, "/users/1", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", rec.Code)
+	}
+	code, _ = generateBindingCode(t, mux, "/users/2/line-binding")
+	sendLineText(t, mux, "U123", "綁定 "+code)
+	if got := getLineUserID(t, mux, "/users/2"); got != "U123" {
+		t.Errorf("Expected line_user_id U123, got %q", got)
+	}
+}
+
+func TestLineBinding_UnknownUser(t *testing.T) {
+	mux, _ := newTestLineWebhook(t)
+
+	if rec := serve(mux, http.MethodPost, "/users/42/line-binding", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+	if rec := serve(mux, http.MethodPost, "/users/abc/line-binding", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestLineBinding_OnlyBindCommandWithCodeBinds(t *testing.T) {
+	mux, fake := newTestLineWebhook(t)
+
+	serve(mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+	code, _ := generateBindingCode(t, mux, "/users/1/line-binding")
+
+	// A bare 綁定 or a question about binding is not a bind attempt.
+	for _, text := range []string{"綁定", "綁定要怎麼用?", "綁定 要 怎麼用"} {
+		sendLineText(t, mux, "U123", text)
+	}
+	for _, reply := range fake.Replies() {
+		if strings.Contains(reply.Messages[0].Text, "無效") {
+			t.Errorf("Expected no invalid code reply, got %q", reply.Messages[0].Text)
+		}
+	}
+	if replies := fake.Replies(); !strings.Contains(replies[0].Messages[0].Text, "綁定碼") {
+		t.Errorf("Expected the bind hint for a bare 綁定, got %+v", replies[0].Messages)
+	}
+
+	sendLineText(t, mux, "U123", "綁定 "+code)
+	if got := getLineUserID(t, mux, "/users/1"); got != "U123" {
+		t.Errorf("Expected line_user_id U123, got %q", got)
+	}
+}
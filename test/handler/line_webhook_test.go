@@ -0,0 +1,232 @@
+package handler_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/handler"
+	"ministry-scheduler/internal/infra"
+	"ministry-scheduler/internal/infra/memory"
+	"ministry-scheduler/internal/usecase"
+)
+
+const (
+	testChannelSecret = "test-channel-secret"
+	testAccessToken   = "test-access-token"
+)
+
+type lineReply struct {
+	ReplyToken string               `json:"replyToken"`
+	Messages   []domain.LineMessage `json:"messages"`
+}
+
+// fakeLineServer records the reply requests the bot sends to the Line API.
+type fakeLineServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	replies []lineReply
+	auth    []string
+}
+
+func newFakeLineServer(t *testing.T) *fakeLineServer {
+	t.Helper()
+
+	fake := &fakeLineServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/bot/message/reply", func(w http.ResponseWriter, r *http.Request) {
+		var reply lineReply
+		if err := json.NewDecoder(r.Body).Decode(&reply); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fake.mu.Lock()
+		fake.replies = append(fake.replies, reply)
+		fake.auth = append(fake.auth, r.Header.Get("Authorization"))
+		fake.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	})
+	fake.Server = httptest.NewServer(mux)
+	t.Cleanup(fake.Close)
+
+	return fake
+}
+
+func (f *fakeLineServer) Replies() []lineReply {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]lineReply(nil), f.replies...)
+}
+
+type testApp struct {
+	mux           *http.ServeMux
+	line          *fakeLineServer
+	notifications *usecase.NotificationUsecase
+}
+
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+
+	store := memory.NewStore()
+	fake := newFakeLineServer(t)
+	client := infra.NewLineHTTPClient(fake.URL, testAccessToken)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	userRepo := memory.NewUserRepository(store)
+	inAppRepo := memory.NewInAppNotificationRepository(store)
+	notificationUsecase := usecase.NewNotificationUsecase(
+		memory.NewNotificationRepository(store),
+		inAppRepo,
+		userRepo,
+		logger,
+		infra.NewInAppNotifier(inAppRepo),
+		infra.NewLineNotifier(client),
+	)
+	transactor := memory.NewTransactor(store)
+	bindingUsecase := usecase.NewLineBindingUsecase(
+		userRepo,
+		memory.NewLineBindingCodeRepository(store),
+		transactor,
+		notificationUsecase,
+	)
+	busyTimeUsecase := usecase.NewBusyTimeUsecase(
+		memory.NewBusyTimeRepository(store), userRepo, transactor, loopbackCalendarFetcher{}, logger)
+	scheduleUsecase := usecase.NewScheduleQueryUsecase(userRepo, time.Local, time.Now)
+	webhook := handler.NewLineWebhookHandler(
+		testChannelSecret, usecase.NewLineBotUsecase(bindingUsecase, scheduleUsecase), client, logger)
+
+	mux := http.NewServeMux()
+	webhook.RegisterRoutes(mux)
+	handler.NewUserHandler(usecase.NewUserUsecase(userRepo)).RegisterRoutes(mux)
+	handler.NewLineBindingHandler(bindingUsecase, "@testbot").RegisterRoutes(mux)
+	handler.NewNotificationHandler(notificationUsecase).RegisterRoutes(mux)
+	handler.NewScheduleQueryHandler(scheduleUsecase).RegisterRoutes(mux)
+	handler.NewBusyTimeHandler(busyTimeUsecase).RegisterRoutes(mux)
+	return &testApp{mux: mux, line: fake, notifications: notificationUsecase}
+}
+
+func newTestLineWebhook(t *testing.T) (*http.ServeMux, *fakeLineServer) {
+	t.Helper()
+
+	app := newTestApp(t)
+	return app.mux, app.line
+}
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(testChannelSecret))
+	mac.Write(body)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func postWebhook(mux *http.ServeMux, body []byte, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/line/webhook", bytes.NewReader(body))
+	req.Header.Set("X-Line-Signature", signature)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLineWebhook_FollowEventReplies(t *testing.T) {
+	mux, fake := newTestLineWebhook(t)
+
+	body := []byte(`{"destination":"U0","events":[{"type":"follow","mode":"active",` +
+		`"replyToken":"reply-1","source":{"type":"user","userId":"U123"}}]}`)
+	rec := postWebhook(mux, body, sign(body))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	replies := fake.Replies()
+	if len(replies) != 1 {
+		t.Fatalf("Expected 1 reply, got %d", len(replies))
+	}
+	if replies[0].ReplyToken != "reply-1" {
+		t.Errorf("Expected reply token reply-1, got %s", replies[0].ReplyToken)
+	}
+	if len(replies[0].Messages) == 0 || replies[0].Messages[0].Type != domain.LineMessageTypeText {
+		t.Errorf("Expected a text message, got %+v", replies[0].Messages)
+	}
+	if fake.auth[0] != "Bearer "+testAccessToken {
+		t.Errorf("Expected bearer token header, got %q", fake.auth[0])
+	}
+}
+
+func TestLineWebhook_MessageAndPostbackEvents(t *testing.T) {
+	mux, fake := newTestLineWebhook(t)
+
+	body := []byte(`{"events":[` +
+		`{"type":"message","mode":"active","replyToken":"reply-1","source":{"type":"user","userId":"U123"},` +
+		`"message":{"id":"1","type":"text","text":"hello"}},` +
+		`{"type":"postback","mode":"active","replyToken":"reply-2","source":{"type":"user","userId":"U123"},` +
+		`"postback":{"data":"action=unknown"}},` +
+		`{"type":"message","mode":"active","replyToken":"reply-3","source":{"type":"user","userId":"U123"},` +
+		`"message":{"id":"2","type":"sticker"}},` +
+		`{"type":"message","mode":"standby","replyToken":"reply-4","source":{"type":"user","userId":"U123"},` +
+		`"message":{"id":"3","type":"text","text":"hello"}}]}`)
+	rec := postWebhook(mux, body, sign(body))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	replies := fake.Replies()
+	if len(replies) != 2 {
+		t.Fatalf("Expected 2 replies, got %d", len(replies))
+	}
+	if replies[0].ReplyToken != "reply-1" || replies[1].ReplyToken != "reply-2" {
+		t.Errorf("Unexpected reply tokens: %s, %s", replies[0].ReplyToken, replies[1].ReplyToken)
+	}
+}
+
+func TestLineWebhook_InvalidSignature(t *testing.T) {
+	mux, fake := newTestLineWebhook(t)
+
+	body := []byte(`{"events":[{"type":"follow","replyToken":"reply-1","source":{"type":"user","userId":"U123"}}]}`)
+
+	tests := []struct {
+		name      string
+		signature string
+	}{
+		{name: "missing signature", signature: ""},
+		{name: "malformed signature", signature: "not-base64!"},
+		{name: "wrong signature", signature: sign([]byte("other body"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := postWebhook(mux, body, tt.signature)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status 401, got %d", rec.Code)
+			}
+		})
+	}
+
+	if len(fake.Replies()) != 0 {
+		t.Errorf("Expected no replies, got %d", len(fake.Replies()))
+	}
+}
+
+func TestLineWebhook_MethodNotAllowed(t *testing.T) {
+	mux, _ := newTestLineWebhook(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/line/webhook", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", rec.Code)
+	}
+}
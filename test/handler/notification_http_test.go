@@ -0,0 +1,153 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"ministry-scheduler/internal/domain"
+)
+
+func TestNotifications_BindingIsDeliveredToInbox(t *testing.T) {
+	app := newTestApp(t)
+
+	serve(app.mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+	code, _ := generateBindingCode(t, app.mux, "/users/1/line-binding")
+	sendLineText(t, app.mux, "U123", "綁定 "+code)
+
+	claimed, err := app.notifications.DispatchDue(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if claimed != 1 {
+		t.Fatalf("Expected 1 notification to be dispatched, got %d", claimed)
+	}
+
+	rec := serve(app.mux, http.MethodGet, "/users/1/notifications", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var resp struct {
+		Notifications []domain.InAppNotification `json:"notifications"`
+		Count         int                        `json:"count"`
+	}
+	if err = json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Count != 1 || resp.Notifications[0].Event != domain.NotificationEventLineBound {
+		t.Errorf("Expected a line_bound notification, got %+v", resp.Notifications)
+	}
+
+	// Only dead letters can be retried.
+	if rec = serve(app.mux, http.MethodPost, "/admin/notifications/1/retry", ""); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", rec.Code)
+	}
+	if rec = serve(app.mux, http.MethodPost, "/admin/notifications/99/retry", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestNotifications_CursorPaging(t *testing.T) {
+	app := newTestApp(t)
+
+	serve(app.mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+	code, _ := generateBindingCode(t, app.mux, "/users/1/line-binding")
+	sendLineText(t, app.mux, "U123", "綁定 "+code)
+	serve(app.mux, http.MethodDelete, "/users/1/line-binding", "")
+	if _, err := app.notifications.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	type inboxPage struct {
+		Notifications []domain.InAppNotification `json:"notifications"`
+		Count         int                        `json:"count"`
+		Total         int                        `json:"total"`
+		NextCursor    string                     `json:"next_cursor"`
+	}
+	get := func(path string) inboxPage {
+		t.Helper()
+		rec := serve(app.mux, http.MethodGet, path, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d: %s", path, rec.Code, rec.Body.String())
+		}
+		var page inboxPage
+		if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return page
+	}
+
+	first := get("/users/1/notifications?limit=1")
+	if first.Count != 1 || first.Total != 2 || first.NextCursor == "" ||
+		first.Notifications[0].Event != domain.NotificationEventLineUnbound {
+		t.Fatalf("Expected the newest of 2 notifications and a cursor, got %+v", first)
+	}
+	second := get("/users/1/notifications?limit=1&cursor=" + first.NextCursor)
+	if second.Count != 1 || second.Total != 2 || second.NextCursor != "" ||
+		second.Notifications[0].Event != domain.NotificationEventLineBound {
+		t.Errorf("Expected the older notification on the last page, got %+v", second)
+	}
+
+	// Malformed cursors and cursors from another listing are rejected.
+	for _, path := range []string{
+		"/users/1/notifications?cursor=bogus",
+		"/admin/notifications/dead-letters?cursor=" + first.NextCursor,
+	} {
+		if rec := serve(app.mux, http.MethodGet, path, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestNotifications_UnknownUser(t *testing.T) {
+	app := newTestApp(t)
+
+	if rec := serve(app.mux, http.MethodGet, "/users/42/notifications", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+	if rec := serve(app.mux, http.MethodGet, "/admin/notifications/dead-letters", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestNotifications_Preferences(t *testing.T) {
+	app := newTestApp(t)
+
+	serve(app.mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+
+	body := `{"channels": {"line_bound": {"in_app": false}},` +
+		`"quiet_hours": {"start": "22:00", "end": "07:00", "timezone": "Asia/Taipei"}}`
+	if rec := serve(app.mux, http.MethodPut, "/users/1/notification-preferences", body); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := serve(app.mux, http.MethodGet, "/users/1", "")
+	var user domain.User
+	if err := json.NewDecoder(rec.Body).Decode(&user); err != nil {
+		t.Fatalf("Failed to decode user: %v", err)
+	}
+	quiet := user.NotificationPreferences.QuietHours
+	if quiet == nil || quiet.Start != "22:00" || quiet.Timezone != "Asia/Taipei" {
+		t.Errorf("Expected quiet hours to be stored, got %+v", quiet)
+	}
+	lineBound := user.NotificationPreferences.Channels[domain.NotificationEventLineBound]
+	if enabled, ok := lineBound[domain.NotificationChannelInApp]; !ok || enabled {
+		t.Errorf("Expected in-app line_bound to be disabled, got %+v", user.NotificationPreferences.Channels)
+	}
+
+	// With in-app turned off, binding enqueues nothing on the registered channels.
+	code, _ := generateBindingCode(t, app.mux, "/users/1/line-binding")
+	sendLineText(t, app.mux, "U123", "綁定 "+code)
+	if claimed, _ := app.notifications.DispatchDue(context.Background()); claimed != 0 {
+		t.Errorf("Expected no notifications, got %d", claimed)
+	}
+
+	body = `{"quiet_hours": {"start": "22:00", "end": "22:00", "timezone": "Asia/Taipei"}}`
+	if rec = serve(app.mux, http.MethodPut, "/users/1/notification-preferences", body); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	if rec = serve(app.mux, http.MethodGet, "/users/42/notification-preferences", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
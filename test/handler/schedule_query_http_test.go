@@ -0,0 +1,40 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+func TestScheduleQuery(t *testing.T) {
+	app := newTestApp(t)
+
+	serve(app.mux, http.MethodPost, "/users", `{"name": "John Doe", "email": "john@example.com"}`)
+
+	rec := serve(app.mux, http.MethodGet, "/users/1/schedule-query?q="+url.QueryEscape("這週誰跟我一起服事？"), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var query domain.ScheduleQuery
+	if err := json.NewDecoder(rec.Body).Decode(&query); err != nil {
+		t.Fatalf("Failed to decode query: %v", err)
+	}
+	if query.Intent != domain.ScheduleIntentCoServers || query.To.Sub(query.From) != 7*24*time.Hour {
+		t.Errorf("Expected a one-week co-servers query, got %+v", query)
+	}
+
+	rec = serve(app.mux, http.MethodGet, "/users/1/schedule-query?q="+url.QueryEscape("你好"), "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	if rec = serve(app.mux, http.MethodGet, "/users/1/schedule-query", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	if rec = serve(app.mux, http.MethodGet, "/users/42/schedule-query?q=abc", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,154 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+)
+
+func listUserNames(t *testing.T, mux *http.ServeMux, path string) []string {
+	t.Helper()
+
+	rec := serve(mux, http.MethodGet, path, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Users []*domain.User `json:"users"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode users: %v", err)
+	}
+
+	names := make([]string, 0, len(body.Users))
+	for _, user := range body.Users {
+		names = append(names, user.Name)
+	}
+	return names
+}
+
+func TestListUsers_Filters(t *testing.T) {
+	app := newTestApp(t)
+	serve(app.mux, http.MethodPost, "/users", `{"name": "王小明", "email": "ming@example.com"}`)
+	serve(app.mux, http.MethodPost, "/users", `{"name": "Alice Wang", "email": "alice@example.com"}`)
+	serve(app.mux, http.MethodPost, "/users", `{"name": "100%_Bob", "email": "bob@example.com"}`)
+
+	today := time.Now().Format("2006-01-02")
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+
+	tests := []struct {
+		query    string
+		expected int
+	}{
+		{"", 3},
+		{"name=" + url.QueryEscape("小明"), 1},
+		{"name=wang", 1},
+		{"name=" + url.QueryEscape("%_"), 1},
+		{"name=_", 1},
+		{"created_from=" + today + "&created_to=" + tomorrow, 3},
+		{"created_to=" + today, 0},
+		{"created_from=" + tomorrow + "&name=alice", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if names := listUserNames(t, app.mux, "/users?"+tt.query); len(names) != tt.expected {
+				t.Errorf("Expected %d users, got %v", tt.expected, names)
+			}
+		})
+	}
+
+	for _, query := range []string{"created_from=2024-13-01", "created_from=" + tomorrow + "&created_to=" + today} {
+		if rec := serve(app.mux, http.MethodGet, "/users?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %q, got %d", query, rec.Code)
+		}
+	}
+}
+
+func TestListUsers_CursorPaging(t *testing.T) {
+	app := newTestApp(t)
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		serve(app.mux, http.MethodPost, "/users", `{"name": "Member", "email": "`+email+`"}`)
+	}
+
+	listPage := func(path string) domain.UserPage {
+		rec := serve(app.mux, http.MethodGet, path, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var page domain.UserPage
+		if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode page: %v", err)
+		}
+		return page
+	}
+
+	first := listPage("/users?limit=2")
+	if first.Total != 3 || first.Count != 2 || first.NextCursor == "" {
+		t.Fatalf("Expected 2 of 3 users and a cursor, got %+v", first)
+	}
+
+	// A user added while paging must not shift the next page.
+	serve(app.mux, http.MethodPost, "/users", `{"name": "Member", "email": "d@example.com"}`)
+
+	second := listPage("/users?limit=2&cursor=" + first.NextCursor)
+	if second.Count != 1 || second.NextCursor != "" || second.Users[0].Email != "a@example.com" {
+		t.Fatalf("Expected only the oldest user on the last page, got %+v", second)
+	}
+
+	if offset := listPage("/users?limit=2&offset=2"); offset.Count != 2 || offset.Total != 4 {
+		t.Errorf("Expected offset paging to keep working, got %+v", offset)
+	}
+	if rec := serve(app.mux, http.MethodGet, "/users?cursor=bogus", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid cursor, got %d", rec.Code)
+	}
+}
+
+func TestListUsers_SearchAndSort(t *testing.T) {
+	app := newTestApp(t)
+	serve(app.mux, http.MethodPost, "/users", `{"name": "陳小華", "email": "hua@example.com"}`)
+	serve(app.mux, http.MethodPost, "/users", `{"name": "Bob Lin", "email": "bob@church.org"}`)
+	serve(app.mux, http.MethodPost, "/users", `{"name": "Alice", "email": "alice@example.com"}`)
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"q=" + url.QueryEscape("小華"), []string{"陳小華"}},
+		{"q=CHURCH", []string{"Bob Lin"}},
+		{"q=example&sort=name", []string{"Alice", "陳小華"}},
+		{"sort=-name", []string{"陳小華", "Bob Lin", "Alice"}},
+		{"sort=created_at", []string{"陳小華", "Bob Lin", "Alice"}},
+		{"", []string{"Alice", "Bob Lin", "陳小華"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			names := listUserNames(t, app.mux, "/users?"+tt.query)
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+
+	rec := serve(app.mux, http.MethodGet, "/users?sort=name&limit=2", "")
+	var page domain.UserPage
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatalf("Failed to decode page: %v", err)
+	}
+	names := listUserNames(t, app.mux, "/users?sort=name&cursor="+page.NextCursor)
+	if len(names) != 1 || names[0] != "陳小華" {
+		t.Errorf("Expected the last name on the next page, got %v", names)
+	}
+
+	for _, query := range []string{"sort=email", "sort=-created_at&cursor=" + page.NextCursor} {
+		if rec = serve(app.mux, http.MethodGet, "/users?"+query, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %q, got %d", query, rec.Code)
+		}
+	}
+}
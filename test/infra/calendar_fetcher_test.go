@@ -0,0 +1,39 @@
+package infra_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ministry-scheduler/internal/infra"
+)
+
+func TestHTTPCalendarFetcher_RefusesNonPublicAddresses(t *testing.T) {
+	fetched := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetched = true
+		_, _ = w.Write([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	fetcher := infra.NewHTTPCalendarFetcher()
+	for _, url := range []string{
+		server.URL + "/cal.ics",
+		"http://localhost:1/cal.ics",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/cal.ics",
+		"http://[::1]:1/cal.ics",
+	} {
+		t.Run(url, func(t *testing.T) {
+			if _, err := fetcher.Fetch(context.Background(), url); !errors.Is(err, infra.ErrCalendarAddressBlocked) {
+				t.Errorf("Expected ErrCalendarAddressBlocked, got %v", err)
+			}
+		})
+	}
+
+	if fetched {
+		t.Error("Expected the loopback server not to be contacted")
+	}
+}
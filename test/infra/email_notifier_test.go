@@ -0,0 +1,235 @@
+package infra_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/infra"
+)
+
+type smtpMessage struct {
+	From string
+	To   []string
+	Data []byte
+}
+
+// fakeSMTPServer speaks just enough SMTP for net/smtp to deliver a message.
+// rejectRcpt, when set, is the reply sent to RCPT TO.
+type fakeSMTPServer struct {
+	listener   net.Listener
+	rejectRcpt string
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	tp := textproto.NewConn(conn)
+	reply := func(line string) { _ = tp.PrintfLine("%s", line) }
+	reply("220 fake.smtp ESMTP")
+
+	var message smtpMessage
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch command {
+		case "EHLO", "HELO":
+			reply("250 fake.smtp")
+		case "MAIL":
+			message = smtpMessage{From: smtpArgument(line)}
+			reply("250 OK")
+		case "RCPT":
+			if s.rejectRcpt != "" {
+				reply(s.rejectRcpt)
+				continue
+			}
+			message.To = append(message.To, smtpArgument(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 Go ahead")
+			data, readErr := tp.ReadDotBytes()
+			if readErr != nil {
+				return
+			}
+			message.Data = data
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+			reply("250 Queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func smtpArgument(line string) string {
+	start, end := strings.Index(line, "<"), strings.LastIndex(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func (s *fakeSMTPServer) Messages() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func (s *fakeSMTPServer) config() infra.SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return infra.SMTPConfig{Host: host, Port: port, From: "Ministry Scheduler <noreply@example.com>"}
+}
+
+func newTestEmailNotifier(t *testing.T, server *fakeSMTPServer) *infra.EmailNotifier {
+	t.Helper()
+
+	notifier, err := infra.NewEmailNotifier(server.config())
+	if err != nil {
+		t.Fatalf("Failed to create email notifier: %v", err)
+	}
+	return notifier
+}
+
+func TestEmailNotifier_SendsTextAndHTML(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := newTestEmailNotifier(t, server)
+
+	user := &domain.User{ID: 1, Name: "王<小明>", Email: "ming@example.com"}
+	notification := &domain.Notification{Title: "Line 帳號已綁定", Body: "你的帳號已綁定 Line。"}
+	if err := notifier.Send(context.Background(), user, notification); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 message, got %d", len(messages))
+	}
+	if messages[0].From != "noreply@example.com" || len(messages[0].To) != 1 || messages[0].To[0] != user.Email {
+		t.Errorf("Unexpected envelope %q -> %v", messages[0].From, messages[0].To)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(messages[0].Data)))
+	if err != nil {
+		t.Fatalf("Failed to parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != notification.Title {
+		t.Errorf("Expected subject %q, got %q (err %v)", notification.Title, subject, err)
+	}
+
+	parts := readEmailParts(t, msg)
+	text := parts["text/plain"]
+	if !strings.Contains(text, "王<小明> 您好") || !strings.Contains(text, notification.Body) {
+		t.Errorf("Unexpected text part %q", text)
+	}
+	html := parts["text/html"]
+	if !strings.Contains(html, "王&lt;小明&gt; 您好") || !strings.Contains(html, notification.Body) {
+		t.Errorf("Expected escaped name and body in HTML part, got %q", html)
+	}
+}
+
+func TestEmailNotifier_PermanentRejectionIsUndeliverable(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.rejectRcpt = "550 No such user"
+	notifier := newTestEmailNotifier(t, server)
+
+	user := &domain.User{ID: 1, Name: "John Doe", Email: "nobody@example.com"}
+	err := notifier.Send(context.Background(), user, &domain.Notification{Title: "title", Body: "body"})
+	if !errors.Is(err, domain.ErrNotificationUndeliverable) {
+		t.Errorf("Expected ErrNotificationUndeliverable, got %v", err)
+	}
+}
+
+func TestEmailNotifier_TemporaryRejectionIsRetryable(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.rejectRcpt = "451 Try again later"
+	notifier := newTestEmailNotifier(t, server)
+
+	user := &domain.User{ID: 1, Name: "John Doe", Email: "john@example.com"}
+	err := notifier.Send(context.Background(), user, &domain.Notification{Title: "title", Body: "body"})
+	if err == nil || errors.Is(err, domain.ErrNotificationUndeliverable) {
+		t.Errorf("Expected a retryable error, got %v", err)
+	}
+}
+
+func TestNewEmailNotifier_InvalidSender(t *testing.T) {
+	if _, err := infra.NewEmailNotifier(infra.SMTPConfig{Host: "localhost", From: "not an address"}); err == nil {
+		t.Error("Expected an error for an invalid sender address")
+	}
+}
+
+// readEmailParts returns the decoded body of each part keyed by media type.
+func readEmailParts(t *testing.T, msg *mail.Message) map[string]string {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Expected multipart/alternative, got %q (err %v)", mediaType, err)
+	}
+
+	parts := make(map[string]string)
+	reader := multipart.NewReader(bufio.NewReader(msg.Body), params["boundary"])
+	for {
+		part, partErr := reader.NextPart()
+		if errors.Is(partErr, io.EOF) {
+			break
+		}
+		if partErr != nil {
+			t.Fatalf("Failed to read part: %v", partErr)
+		}
+
+		// NextPart decodes quoted-printable bodies.
+		data, readErr := io.ReadAll(part)
+		if readErr != nil {
+			t.Fatalf("Failed to read part body: %v", readErr)
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[partType] = string(data)
+	}
+	return parts
+}
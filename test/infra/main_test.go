@@ -0,0 +1,87 @@
+package infra_test
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+)
+
+// Set POSTGRES_TEST_EMBEDDED (and leave POSTGRES_TEST_URL unset) to run the
+// PostgreSQL tests against a throwaway local server. The first run downloads
+// the PostgreSQL 16 binaries into ~/.embedded-postgres-go. initdb refuses to
+// run as root.
+const postgresTestEmbeddedEnv = "POSTGRES_TEST_EMBEDDED"
+
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	if os.Getenv(postgresTestURLEnv) != "" || os.Getenv(postgresTestEmbeddedEnv) == "" {
+		return m.Run()
+	}
+
+	stop, err := startEmbeddedPostgres()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start embedded PostgreSQL: %v\n", err)
+		return 1
+	}
+	defer stop()
+
+	return m.Run()
+}
+
+// startEmbeddedPostgres starts a server on a free port and points
+// POSTGRES_TEST_URL at it.
+func startEmbeddedPostgres() (func(), error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	runtimePath, err := os.MkdirTemp("", "embedded-postgres-")
+	if err != nil {
+		return nil, err
+	}
+
+	var logs bytes.Buffer
+	config := embeddedpostgres.DefaultConfig().
+		Version(embeddedpostgres.V16).
+		Port(port).
+		RuntimePath(runtimePath).
+		Logger(&logs)
+	server := embeddedpostgres.NewDatabase(config)
+	if err = server.Start(); err != nil {
+		_ = os.RemoveAll(runtimePath)
+		return nil, fmt.Errorf("%w\n%s", err, logs.String())
+	}
+
+	if err = os.Setenv(postgresTestURLEnv, config.GetConnectionURL()+"?sslmode=disable"); err != nil {
+		_ = server.Stop()
+		_ = os.RemoveAll(runtimePath)
+		return nil, err
+	}
+
+	return func() {
+		_ = server.Stop()
+		_ = os.RemoveAll(runtimePath)
+	}, nil
+}
+
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	addr, ok := listener.Addr().(*net.TCPAddr)
+	if !ok {
+		return 0, fmt.Errorf("unexpected listener address %v", listener.Addr())
+	}
+	return uint32(addr.Port), nil
+}
@@ -0,0 +1,41 @@
+package infra_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/infra/memory"
+)
+
+func TestMemoryStore_ConcurrentUse(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	transactor := memory.NewTransactor(store)
+
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = transactor.WithinTx(ctx, func(ctx context.Context) error {
+				_, err := users.Create(ctx, &domain.User{
+					Name: "User", Email: fmt.Sprintf("user%d@example.com", i), CreatedAt: time.Now(),
+				})
+				return err
+			})
+			_, _ = users.List(ctx, domain.UserFilter{}, domain.UserSortNewest, domain.Page{Limit: 10})
+		}()
+	}
+	wg.Wait()
+
+	count, err := users.Count(ctx, domain.UserFilter{})
+	if err != nil || count != workers {
+		t.Errorf("Expected %d users, got %d (%v)", workers, count, err)
+	}
+}
@@ -0,0 +1,193 @@
+package infra_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/infra"
+	"ministry-scheduler/internal/infra/postgres"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := infra.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrations, err := infra.Migrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	pending, err := infra.PendingMigrations(ctx, db)
+	if err != nil || len(pending) != len(migrations) {
+		t.Fatalf("Expected all %d migrations pending, got %d (%v)", len(migrations), len(pending), err)
+	}
+	// A dry run must not touch the database.
+	if _, err = db.ExecContext(ctx, `SELECT 1 FROM users`); err == nil {
+		t.Fatal("Expected PendingMigrations not to create tables")
+	}
+
+	applied, err := infra.Migrate(ctx, db)
+	if err != nil || len(applied) != len(migrations) {
+		t.Fatalf("Expected %d migrations applied, got %d (%v)", len(migrations), len(applied), err)
+	}
+	if applied, err = infra.Migrate(ctx, db); err != nil || len(applied) != 0 {
+		t.Errorf("Expected a second run to apply nothing, got %d (%v)", len(applied), err)
+	}
+
+	var recorded int
+	if err = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations`).Scan(&recorded); err != nil {
+		t.Fatalf("Failed to count migrations: %v", err)
+	}
+	if recorded != len(migrations) {
+		t.Errorf("Expected %d recorded migrations, got %d", len(migrations), recorded)
+	}
+}
+
+func TestMigrate_AdoptsLegacyDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	// Databases from before versioned migrations have the tables but no history.
+	legacy := []string{
+		`CREATE TABLE users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			email TEXT UNIQUE NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		)`,
+		`CREATE INDEX idx_users_created_at ON users (created_at)`,
+		`INSERT INTO users (name, email, created_at, updated_at)
+			VALUES ('John Doe', 'john@example.com', '2024-01-15 10:30:00+00:00', '2024-01-15 10:30:00+00:00')`,
+	}
+	for _, query := range legacy {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			t.Fatalf("Failed to create legacy schema: %v", err)
+		}
+	}
+
+	if _, err := infra.Migrate(ctx, db); err != nil {
+		t.Fatalf("Failed to migrate legacy database: %v", err)
+	}
+
+	var users int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&users); err != nil || users != 1 {
+		t.Errorf("Expected the existing user to survive, got %d (%v)", users, err)
+	}
+	var indexes int
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'idx_users_created_at'`).Scan(&indexes)
+	if err != nil || indexes != 0 {
+		t.Errorf("Expected the superseded index to be dropped, got %d (%v)", indexes, err)
+	}
+}
+
+func TestMigrate_NormalizesUserTimestampsToUTC(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrations, err := infra.Migrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	// Apply everything before 0006 by hand, then add rows written with the
+	// server's offset as older versions did.
+	for _, migration := range migrations[:5] {
+		if _, err = db.ExecContext(ctx, migration.SQL); err != nil {
+			t.Fatalf("Failed to apply %s: %v", migration.Name, err)
+		}
+	}
+	legacy := `INSERT INTO users (name, email, created_at, updated_at) VALUES
+		('Early', 'early@example.com', '2024-01-16 07:30:00.5+08:00', '2024-01-16 07:30:00.5+08:00'),
+		('Late', 'late@example.com', '2024-01-15 20:00:00-05:00', '2024-01-16 01:00:00+00:00')`
+	if _, err = db.ExecContext(ctx, legacy); err != nil {
+		t.Fatalf("Failed to insert legacy users: %v", err)
+	}
+
+	if _, err = infra.Migrate(ctx, db); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT created_at || '', updated_at || '' FROM users ORDER BY id`)
+	if err != nil {
+		t.Fatalf("Failed to read users: %v", err)
+	}
+	defer rows.Close()
+	expected := [][2]string{
+		{"2024-01-15 23:30:00.5+00:00", "2024-01-15 23:30:00.5+00:00"},
+		{"2024-01-16 01:00:00+00:00", "2024-01-16 01:00:00+00:00"},
+	}
+	for i := 0; rows.Next(); i++ {
+		var createdAt, updatedAt string
+		if err = rows.Scan(&createdAt, &updatedAt); err != nil {
+			t.Fatalf("Failed to scan: %v", err)
+		}
+		if i >= len(expected) || createdAt != expected[i][0] || updatedAt != expected[i][1] {
+			t.Errorf("Row %d: got %q, %q", i, createdAt, updatedAt)
+		}
+	}
+
+	// Text comparison now agrees with time order.
+	from := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+	users, err := infra.NewSQLUserRepository(db).List(ctx,
+		domain.UserFilter{CreatedFrom: from}, domain.UserSortOldest, domain.Page{Limit: 10})
+	if err != nil || len(users) != 1 || users[0].Name != "Late" {
+		t.Errorf("Expected only Late created after %v, got %v (%v)", from, users, err)
+	}
+}
+
+func TestMigrate_PostgresMirrorsSQLite(t *testing.T) {
+	sqlite, err := infra.Migrations()
+	if err != nil {
+		t.Fatalf("Failed to load SQLite migrations: %v", err)
+	}
+	pg, err := postgres.Migrations()
+	if err != nil {
+		t.Fatalf("Failed to load PostgreSQL migrations: %v", err)
+	}
+
+	if len(pg) != len(sqlite) {
+		t.Fatalf("Expected %d PostgreSQL migrations, got %d", len(sqlite), len(pg))
+	}
+	for i := range sqlite {
+		if pg[i].Version != sqlite[i].Version || pg[i].Name != sqlite[i].Name {
+			t.Errorf("Expected migration %d_%s, got %d_%s", sqlite[i].Version, sqlite[i].Name, pg[i].Version, pg[i].Name)
+		}
+	}
+}
+
+func TestMigrate_RefusesNewerDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+
+	if _, err := infra.Migrate(ctx, db); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, applied_at) VALUES (9999, 'from_the_future', '2030-01-01')`)
+	if err != nil {
+		t.Fatalf("Failed to record migration: %v", err)
+	}
+
+	if _, err = infra.Migrate(ctx, db); !errors.Is(err, infra.ErrDatabaseTooNew) {
+		t.Errorf("Expected ErrDatabaseTooNew, got %v", err)
+	}
+	if _, err = infra.PendingMigrations(ctx, db); !errors.Is(err, infra.ErrDatabaseTooNew) {
+		t.Errorf("Expected ErrDatabaseTooNew from a dry run, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package infra_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/infra"
+	"ministry-scheduler/internal/infra/memory"
+	"ministry-scheduler/internal/infra/postgres"
+	"ministry-scheduler/test/contract"
+)
+
+// The PostgreSQL tests need a server; set POSTGRES_TEST_URL to run them, or
+// POSTGRES_TEST_EMBEDDED to start one (see main_test.go). On CI
+// (CI set, as GitHub Actions does) they fail instead of skipping without it.
+// Each test works in its own schema, which is dropped afterwards.
+const postgresTestURLEnv = "POSTGRES_TEST_URL"
+
+func TestRepositoryContract_SQLite(t *testing.T) {
+	contract.RunAll(t, func(t *testing.T) contract.Repositories {
+		db := openTestDB(t)
+		if _, err := infra.Migrate(context.Background(), db); err != nil {
+			t.Fatalf("Failed to migrate: %v", err)
+		}
+		return contract.Repositories{
+			Users:              infra.NewSQLUserRepository(db),
+			LineBindingCodes:   infra.NewSQLLineBindingCodeRepository(db),
+			Notifications:      infra.NewSQLNotificationRepository(db),
+			InAppNotifications: infra.NewSQLInAppNotificationRepository(db),
+			BusyTimes:          infra.NewSQLBusyTimeRepository(db),
+			Transactor:         infra.NewSQLTransactor(db),
+		}
+	})
+}
+
+func TestRepositoryContract_Postgres(t *testing.T) {
+	contract.RunAll(t, func(t *testing.T) contract.Repositories {
+		db := openPostgresTestDB(t)
+		return contract.Repositories{
+			Users:              postgres.NewUserRepository(db),
+			LineBindingCodes:   postgres.NewLineBindingCodeRepository(db),
+			Notifications:      postgres.NewNotificationRepository(db),
+			InAppNotifications: postgres.NewInAppNotificationRepository(db),
+			BusyTimes:          postgres.NewBusyTimeRepository(db),
+			Transactor:         postgres.NewTransactor(db),
+		}
+	})
+}
+
+func TestRepositoryContract_Memory(t *testing.T) {
+	contract.RunAll(t, func(*testing.T) contract.Repositories {
+		store := memory.NewStore()
+		return contract.Repositories{
+			Users:              memory.NewUserRepository(store),
+			LineBindingCodes:   memory.NewLineBindingCodeRepository(store),
+			Notifications:      memory.NewNotificationRepository(store),
+			InAppNotifications: memory.NewInAppNotificationRepository(store),
+			BusyTimes:          memory.NewBusyTimeRepository(store),
+			Transactor:         memory.NewTransactor(store),
+		}
+	})
+}
+
+func openPostgresTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	databaseURL := os.Getenv(postgresTestURLEnv)
+	if databaseURL == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatalf("%s must be set on CI", postgresTestURLEnv)
+		}
+		t.Skipf("%s not set", postgresTestURLEnv)
+	}
+
+	ctx := context.Background()
+	admin, err := postgres.OpenDB(databaseURL)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = admin.Close() })
+
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err = admin.ExecContext(ctx, `CREATE SCHEMA `+schema); err != nil {
+		t.Fatalf("Failed to create schema: %v", err)
+	}
+	t.Cleanup(func() { _, _ = admin.ExecContext(ctx, `DROP SCHEMA `+schema+` CASCADE`) })
+
+	// lib/pq passes unknown parameters on as run-time settings.
+	separator := " "
+	if strings.Contains(databaseURL, "://") {
+		separator = "?"
+		if strings.Contains(databaseURL, "?") {
+			separator = "&"
+		}
+	}
+	db, err := postgres.InitializeDB(databaseURL + separator + "search_path=" + schema)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
@@ -0,0 +1,51 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/infra/memory"
+	"ministry-scheduler/internal/usecase"
+)
+
+type failingCalendarFetcher struct {
+	err error
+}
+
+func (f failingCalendarFetcher) Fetch(context.Context, string) ([]byte, error) {
+	return nil, f.err
+}
+
+func TestBusyTimeUsecase_FetchErrorsDoNotLogTheURL(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	user, err := users.Create(ctx, &domain.User{Name: "John Doe", Email: "john@example.com", CreatedAt: time.Now()})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	const calendarURL = "https://calendar.example.com/private-s3cr3t/basic.ics"
+	fetcher := failingCalendarFetcher{err: &url.Error{Op: "Get", URL: calendarURL, Err: errors.New("connection refused")}}
+	var logs bytes.Buffer
+	uc := usecase.NewBusyTimeUsecase(memory.NewBusyTimeRepository(store), users, memory.NewTransactor(store),
+		fetcher, slog.New(slog.NewTextHandler(&logs, nil)))
+
+	if _, err = uc.Subscribe(ctx, user.ID, calendarURL); !errors.Is(err, domain.ErrCalendarUnavailable) {
+		t.Fatalf("Expected ErrCalendarUnavailable, got %v", err)
+	}
+	if strings.Contains(logs.String(), "s3cr3t") {
+		t.Errorf("Expected the subscription URL to stay out of the log, got %s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "host=calendar.example.com") ||
+		!strings.Contains(logs.String(), "connection refused") {
+		t.Errorf("Expected the host and the cause to be logged, got %s", logs.String())
+	}
+}
@@ -0,0 +1,254 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/infra"
+	"ministry-scheduler/internal/infra/memory"
+	"ministry-scheduler/internal/usecase"
+)
+
+type mockLineBindingCodeRepository struct {
+	codes map[string]*domain.LineBindingCode
+}
+
+func newMockLineBindingCodeRepository() *mockLineBindingCodeRepository {
+	return &mockLineBindingCodeRepository{
+		codes: make(map[string]*domain.LineBindingCode),
+	}
+}
+
+func (m *mockLineBindingCodeRepository) Save(_ context.Context, code *domain.LineBindingCode) error {
+	for value, existing := range m.codes {
+		if existing.UserID == code.UserID {
+			delete(m.codes, value)
+		}
+	}
+	m.codes[code.Code] = code
+	return nil
+}
+
+func (m *mockLineBindingCodeRepository) Consume(_ context.Context, code string) (*domain.LineBindingCode, error) {
+	bindingCode, exists := m.codes[code]
+	if !exists {
+		return nil, domain.ErrInvalidBindingCode
+	}
+	delete(m.codes, code)
+	return bindingCode, nil
+}
+
+type lineBindingFixture struct {
+	users         *memory.UserRepository
+	codes         *mockLineBindingCodeRepository
+	tx            *mockTransactor
+	notifications *mockNotificationRepository
+	uc            *usecase.LineBindingUsecase
+}
+
+func newLineBindingFixture() *lineBindingFixture {
+	notifications := newNotificationFixture(&fakeNotifier{channel: domain.NotificationChannelInApp})
+	users := notifications.users
+	_, _ = users.Create(context.Background(), &domain.User{Name: "Jane Doe", Email: "jane@example.com"})
+	codes := newMockLineBindingCodeRepository()
+	tx := &mockTransactor{}
+
+	return &lineBindingFixture{
+		users:         users,
+		codes:         codes,
+		tx:            tx,
+		notifications: notifications.repo,
+		uc:            usecase.NewLineBindingUsecase(users, codes, tx, notifications.uc),
+	}
+}
+
+func lineUserID(t *testing.T, users *memory.UserRepository, userID int64) string {
+	t.Helper()
+
+	user, err := users.GetByID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	return user.LineUserID
+}
+
+func bindLineAccount(t *testing.T, users *memory.UserRepository, userID int64, lineUserID string) {
+	t.Helper()
+
+	if err := users.BindLineAccount(context.Background(), userID, lineUserID); err != nil {
+		t.Fatalf("Failed to bind: %v", err)
+	}
+}
+
+func TestLineBindingUsecase_GenerateCode(t *testing.T) {
+	f := newLineBindingFixture()
+	codes, uc := f.codes, f.uc
+
+	code, err := uc.GenerateCode(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(code.Code) != domain.LineBindingCodeLength {
+		t.Errorf("Expected code length %d, got %d", domain.LineBindingCodeLength, len(code.Code))
+	}
+	if !code.ExpiresAt.After(time.Now()) {
+		t.Error("Expected code to expire in the future")
+	}
+
+	if _, err = uc.GenerateCode(context.Background(), 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(codes.codes) != 1 {
+		t.Errorf("Expected previous code to be replaced, got %d codes", len(codes.codes))
+	}
+
+	_, err = uc.GenerateCode(context.Background(), 999)
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestLineBindingUsecase_Bind(t *testing.T) {
+	f := newLineBindingFixture()
+	users, uc := f.users, f.uc
+
+	code, err := uc.GenerateCode(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	user, err := uc.Bind(context.Background(), "U123", " "+code.Code+" ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.LineUserID != "U123" || lineUserID(t, users, 1) != "U123" {
+		t.Errorf("Expected Line user U123 to be bound, got %q", user.LineUserID)
+	}
+	if f.tx.calls != 1 || len(f.notifications.notifications) != 1 {
+		t.Errorf("Expected binding and notification in one transaction, got %d tx and %d notifications",
+			f.tx.calls, len(f.notifications.notifications))
+	}
+	if n := f.notifications.notifications[1]; n.Event != domain.NotificationEventLineBound || n.UserID != 1 {
+		t.Errorf("Unexpected notification %+v", n)
+	}
+
+	_, err = uc.Bind(context.Background(), "U123", code.Code)
+	if !errors.Is(err, domain.ErrInvalidBindingCode) {
+		t.Errorf("Expected ErrInvalidBindingCode on reuse, got %v", err)
+	}
+}
+
+func TestLineBindingUsecase_BindExpiredCode(t *testing.T) {
+	f := newLineBindingFixture()
+	codes, uc := f.codes, f.uc
+
+	codes.codes["ABCDEFGH"] = &domain.LineBindingCode{
+		Code:      "ABCDEFGH",
+		UserID:    1,
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+
+	_, err := uc.Bind(context.Background(), "U123", "abcdefgh")
+	if !errors.Is(err, domain.ErrInvalidBindingCode) {
+		t.Errorf("Expected ErrInvalidBindingCode, got %v", err)
+	}
+}
+
+func TestLineBindingUsecase_BindLineAccountAlreadyBound(t *testing.T) {
+	f := newLineBindingFixture()
+	users, uc := f.users, f.uc
+	bindLineAccount(t, users, 2, "U123")
+
+	code, err := uc.GenerateCode(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err = uc.Bind(context.Background(), "U123", code.Code)
+	if !errors.Is(err, domain.ErrLineAccountBound) {
+		t.Errorf("Expected ErrLineAccountBound, got %v", err)
+	}
+}
+
+func TestLineBindingUsecase_FailedBindKeepsCode(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+	inApp := memory.NewInAppNotificationRepository(store)
+	notifications := usecase.NewNotificationUsecase(memory.NewNotificationRepository(store), inApp, users,
+		slog.New(slog.NewTextHandler(io.Discard, nil)), infra.NewInAppNotifier(inApp))
+	uc := usecase.NewLineBindingUsecase(
+		users, memory.NewLineBindingCodeRepository(store), memory.NewTransactor(store), notifications)
+
+	now := time.Now()
+	john, err := users.Create(ctx, &domain.User{Name: "John Doe", Email: "john@example.com", CreatedAt: now})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	jane, err := users.Create(ctx, &domain.User{Name: "Jane Doe", Email: "jane@example.com", CreatedAt: now})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if err = users.BindLineAccount(ctx, jane.ID, "U123"); err != nil {
+		t.Fatalf("Failed to bind: %v", err)
+	}
+
+	code, err := uc.GenerateCode(ctx, john.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = uc.Bind(ctx, "U123", code.Code); !errors.Is(err, domain.ErrLineAccountBound) {
+		t.Fatalf("Expected ErrLineAccountBound, got %v", err)
+	}
+
+	// The conflict rolled back the transaction, so the code still works.
+	user, err := uc.Bind(ctx, "U456", code.Code)
+	if err != nil || user.ID != john.ID || user.LineUserID != "U456" {
+		t.Errorf("Expected John bound to U456, got %+v (%v)", user, err)
+	}
+}
+
+func TestLineBindingUsecase_Rebind(t *testing.T) {
+	f := newLineBindingFixture()
+	users, uc := f.users, f.uc
+	bindLineAccount(t, users, 1, "U-old")
+
+	code, err := uc.GenerateCode(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err = uc.Bind(context.Background(), "U-new", code.Code); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := lineUserID(t, users, 1); got != "U-new" {
+		t.Errorf("Expected Line user U-new, got %q", got)
+	}
+}
+
+func TestLineBindingUsecase_Unbind(t *testing.T) {
+	f := newLineBindingFixture()
+	users, uc := f.users, f.uc
+	bindLineAccount(t, users, 1, "U123")
+
+	if err := uc.Unbind(context.Background(), 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := lineUserID(t, users, 1); got != "" {
+		t.Errorf("Expected binding to be removed, got %q", got)
+	}
+
+	if err := uc.Unbind(context.Background(), 1); !errors.Is(err, domain.ErrLineNotBound) {
+		t.Errorf("Expected ErrLineNotBound, got %v", err)
+	}
+
+	if err := uc.UnbindLineUser(context.Background(), "U123"); !errors.Is(err, domain.ErrLineNotBound) {
+		t.Errorf("Expected ErrLineNotBound, got %v", err)
+	}
+}
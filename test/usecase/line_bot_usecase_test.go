@@ -0,0 +1,94 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/infra/memory"
+	"ministry-scheduler/internal/usecase"
+)
+
+func newLineBotFixture(t *testing.T) (*memory.UserRepository, *usecase.LineBotUsecase) {
+	t.Helper()
+
+	f := newLineBindingFixture()
+	bindLineAccount(t, f.users, 1, "U123")
+	return f.users, usecase.NewLineBotUsecase(f.uc, usecase.NewScheduleQueryUsecase(f.users, time.Local, time.Now))
+}
+
+func TestLineBotUsecase_TextMessageRepliesWithHelp(t *testing.T) {
+	_, bot := newLineBotFixture(t)
+
+	messages, err := bot.HandleTextMessage(context.Background(), "U123", "hello")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(messages) != 2 || messages[1].Type != domain.LineMessageTypeText {
+		t.Fatalf("Expected help text, got %+v", messages)
+	}
+	// The rich menu commands are listed as coming soon, not offered as buttons.
+	if !strings.Contains(messages[1].Text, "📅 我的服事") {
+		t.Errorf("Expected the menu commands to be listed, got %q", messages[1].Text)
+	}
+}
+
+func TestLineBotUsecase_PostbackCommandsAreNotAvailable(t *testing.T) {
+	_, bot := newLineBotFixture(t)
+
+	actions := []string{
+		usecase.LineActionMySchedule,
+		usecase.LineActionTeamSchedule,
+		usecase.LineActionRequestLeave,
+		usecase.LineActionRequestSwap,
+		usecase.LineActionAnnouncements,
+		usecase.LineActionLiveStatus,
+	}
+	var data []string
+	for _, action := range actions {
+		data = append(data, usecase.LinePostbackData(action))
+	}
+
+	for _, data := range append(data, "action=unknown", "%zz", "") {
+		messages, err := bot.HandlePostback(context.Background(), "U123", data)
+		if err != nil {
+			t.Fatalf("Expected no error for %q, got %v", data, err)
+		}
+		if len(messages) != 1 || messages[0].Type != domain.LineMessageTypeText {
+			t.Errorf("Expected a text reply for %q, got %+v", data, messages)
+		}
+	}
+}
+
+func TestLineBotUsecase_ScheduleQuestion(t *testing.T) {
+	_, bot := newLineBotFixture(t)
+
+	messages, err := bot.HandleTextMessage(context.Background(), "U123", "這週誰跟我一起服事？")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(messages) != 1 || messages[0].Type != domain.LineMessageTypeFlex {
+		t.Fatalf("Expected a flex card, got %+v", messages)
+	}
+	if messages[0].AltText != "👥 一起服事的同工" {
+		t.Errorf("Expected co-servers card, got %q", messages[0].AltText)
+	}
+
+	bubble, ok := messages[0].Contents.(domain.FlexBubble)
+	if !ok {
+		t.Fatalf("Expected FlexBubble contents, got %T", messages[0].Contents)
+	}
+	if len(bubble.Body.Contents) < 2 || bubble.Body.Contents[1].Contents[0].Text != "期間" {
+		t.Errorf("Expected card to show the resolved period, got %+v", bubble.Body)
+	}
+
+	messages, err = bot.HandleTextMessage(context.Background(), "U-unbound", "我下週有服事嗎？")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(messages) != 1 || messages[0].Type != domain.LineMessageTypeText {
+		t.Errorf("Expected a text binding hint, got %+v", messages)
+	}
+}
@@ -0,0 +1,417 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/infra/memory"
+	"ministry-scheduler/internal/usecase"
+)
+
+type mockTransactor struct {
+	calls int
+}
+
+func (m *mockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	return fn(ctx)
+}
+
+type mockNotificationRepository struct {
+	notifications map[int64]*domain.Notification
+	updateErrs    map[int64]error
+	nextID        int64
+}
+
+func newMockNotificationRepository() *mockNotificationRepository {
+	return &mockNotificationRepository{
+		notifications: make(map[int64]*domain.Notification),
+		updateErrs:    make(map[int64]error),
+		nextID:        1,
+	}
+}
+
+func (m *mockNotificationRepository) Enqueue(_ context.Context, notifications []*domain.Notification) error {
+	for _, notification := range notifications {
+		notification.ID = m.nextID
+		m.nextID++
+		m.notifications[notification.ID] = notification
+	}
+	return nil
+}
+
+func (m *mockNotificationRepository) GetByID(_ context.Context, id int64) (*domain.Notification, error) {
+	notification, exists := m.notifications[id]
+	if !exists {
+		return nil, domain.ErrNotificationNotFound
+	}
+	return notification, nil
+}
+
+// ClaimDue ignores next_attempt_at so tests can drive retries without waiting
+// for the backoff.
+func (m *mockNotificationRepository) ClaimDue(
+	_ context.Context,
+	_ time.Time,
+	limit int,
+	_ time.Time,
+) ([]*domain.Notification, error) {
+	var claimed []*domain.Notification
+	for _, id := range m.ids() {
+		notification := m.notifications[id]
+		if notification.Status == domain.NotificationStatusPending && len(claimed) < limit {
+			claimed = append(claimed, notification)
+		}
+	}
+	return claimed, nil
+}
+
+func (m *mockNotificationRepository) UpdateDelivery(_ context.Context, notification *domain.Notification) error {
+	if _, exists := m.notifications[notification.ID]; !exists {
+		return domain.ErrNotificationNotFound
+	}
+	if err := m.updateErrs[notification.ID]; err != nil {
+		return err
+	}
+	m.notifications[notification.ID] = notification
+	return nil
+}
+
+func (m *mockNotificationRepository) ListByStatus(
+	_ context.Context,
+	status domain.NotificationStatus,
+	page domain.Page,
+) ([]*domain.Notification, error) {
+	var notifications []*domain.Notification
+	count := 0
+	for _, id := range slices.Backward(m.ids()) {
+		notification := m.notifications[id]
+		if notification.Status != status || (page.After != nil && id >= page.After.ID) {
+			continue
+		}
+		if count >= page.Offset && len(notifications) < page.Limit {
+			notifications = append(notifications, notification)
+		}
+		count++
+	}
+	return notifications, nil
+}
+
+func (m *mockNotificationRepository) CountByStatus(_ context.Context, status domain.NotificationStatus) (int, error) {
+	count := 0
+	for _, notification := range m.notifications {
+		if notification.Status == status {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockNotificationRepository) ids() []int64 {
+	ids := make([]int64, 0, len(m.notifications))
+	for id := range m.notifications {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+type mockInAppNotificationRepository struct {
+	notifications []*domain.InAppNotification
+}
+
+func (m *mockInAppNotificationRepository) Create(_ context.Context, notification *domain.InAppNotification) error {
+	m.notifications = append(m.notifications, notification)
+	return nil
+}
+
+func (m *mockInAppNotificationRepository) ListByUser(
+	_ context.Context,
+	userID int64,
+	_ domain.Page,
+) ([]*domain.InAppNotification, error) {
+	var notifications []*domain.InAppNotification
+	for _, notification := range m.notifications {
+		if notification.UserID == userID {
+			notifications = append(notifications, notification)
+		}
+	}
+	return notifications, nil
+}
+
+func (m *mockInAppNotificationRepository) CountByUser(_ context.Context, userID int64) (int, error) {
+	count := 0
+	for _, notification := range m.notifications {
+		if notification.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type fakeNotifier struct {
+	channel domain.NotificationChannel
+	err     error
+	sent    []*domain.Notification
+}
+
+func (f *fakeNotifier) Channel() domain.NotificationChannel {
+	return f.channel
+}
+
+func (f *fakeNotifier) Send(_ context.Context, _ *domain.User, notification *domain.Notification) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, notification)
+	return nil
+}
+
+type notificationFixture struct {
+	users *memory.UserRepository
+	repo  *mockNotificationRepository
+	inApp *mockInAppNotificationRepository
+	uc    *usecase.NotificationUsecase
+}
+
+func newNotificationFixture(notifiers ...domain.Notifier) *notificationFixture {
+	users := memory.NewUserRepository(memory.NewStore())
+	_, _ = users.Create(context.Background(), &domain.User{Name: "John Doe", Email: "john@example.com"})
+	repo := newMockNotificationRepository()
+	inApp := &mockInAppNotificationRepository{}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return &notificationFixture{
+		users: users,
+		repo:  repo,
+		inApp: inApp,
+		uc:    usecase.NewNotificationUsecase(repo, inApp, users, logger, notifiers...),
+	}
+}
+
+func TestNotificationUsecase_EnqueueUsesRegisteredDefaultChannels(t *testing.T) {
+	f := newNotificationFixture(
+		&fakeNotifier{channel: domain.NotificationChannelLine},
+		&fakeNotifier{channel: domain.NotificationChannelInApp},
+	)
+
+	err := f.uc.Enqueue(context.Background(), 1, domain.NotificationEventLineBound, "title", "body")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// line_bound goes to email and in-app; email has no notifier registered.
+	if len(f.repo.notifications) != 1 {
+		t.Fatalf("Expected 1 outbox entry, got %d", len(f.repo.notifications))
+	}
+	notification := f.repo.notifications[1]
+	if notification.Channel != domain.NotificationChannelInApp || notification.Status != domain.NotificationStatusPending {
+		t.Errorf("Unexpected outbox entry %+v", notification)
+	}
+}
+
+func TestNotificationUsecase_DispatchDueDelivers(t *testing.T) {
+	notifier := &fakeNotifier{channel: domain.NotificationChannelInApp}
+	f := newNotificationFixture(notifier)
+
+	if err := f.uc.Enqueue(context.Background(), 1, domain.NotificationEventLineBound, "title", "body"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	claimed, err := f.uc.DispatchDue(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if claimed != 1 || len(notifier.sent) != 1 {
+		t.Fatalf("Expected 1 delivery, got claimed=%d sent=%d", claimed, len(notifier.sent))
+	}
+
+	notification := f.repo.notifications[1]
+	if notification.Status != domain.NotificationStatusSent || notification.SentAt == nil {
+		t.Errorf("Expected notification to be marked sent, got %+v", notification)
+	}
+
+	if claimed, _ = f.uc.DispatchDue(context.Background()); claimed != 0 {
+		t.Errorf("Expected nothing left to dispatch, got %d", claimed)
+	}
+}
+
+func TestNotificationUsecase_DispatchDueContinuesPastFailedRows(t *testing.T) {
+	notifier := &fakeNotifier{channel: domain.NotificationChannelInApp}
+	f := newNotificationFixture(notifier)
+
+	for range 3 {
+		if err := f.uc.Enqueue(context.Background(), 1, domain.NotificationEventLineBound, "title", "body"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	f.repo.updateErrs[1] = domain.ErrNotificationLeaseLost
+	f.repo.updateErrs[2] = errors.New("connection reset")
+
+	claimed, err := f.uc.DispatchDue(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if claimed != 3 || len(notifier.sent) != 3 {
+		t.Fatalf("Expected the whole batch to be attempted, got claimed=%d sent=%d", claimed, len(notifier.sent))
+	}
+	if notification := f.repo.notifications[3]; notification.Status != domain.NotificationStatusSent {
+		t.Errorf("Expected the last notification to be marked sent, got %+v", notification)
+	}
+}
+
+func TestNotificationUsecase_RetriesWithBackoffThenDeadLetters(t *testing.T) {
+	notifier := &fakeNotifier{channel: domain.NotificationChannelInApp, err: errors.New("temporary failure")}
+	f := newNotificationFixture(notifier)
+
+	if err := f.uc.Enqueue(context.Background(), 1, domain.NotificationEventLineBound, "title", "body"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	before := time.Now()
+	if _, err := f.uc.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	notification := f.repo.notifications[1]
+	if notification.Status != domain.NotificationStatusPending || notification.Attempts != 1 {
+		t.Fatalf("Expected pending after first failure, got %+v", notification)
+	}
+	if notification.NextAttemptAt.Before(before.Add(domain.NotificationBackoff(1))) {
+		t.Errorf("Expected next attempt to be backed off, got %v", notification.NextAttemptAt)
+	}
+
+	for range domain.MaxNotificationAttempts - 1 {
+		if _, err := f.uc.DispatchDue(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if notification.Status != domain.NotificationStatusDead || notification.LastError != "temporary failure" {
+		t.Errorf("Expected dead letter after %d attempts, got %+v", domain.MaxNotificationAttempts, notification)
+	}
+
+	dead, err := f.uc.ListDeadLetters(context.Background(), domain.Page{})
+	if err != nil || dead.Count != 1 || dead.Total != 1 || dead.NextCursor != "" {
+		t.Fatalf("Expected 1 dead letter, got %+v (err %v)", dead, err)
+	}
+
+	notifier.err = nil
+	if _, err = f.uc.Retry(context.Background(), notification.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = f.uc.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if notification.Status != domain.NotificationStatusSent {
+		t.Errorf("Expected retried notification to be sent, got %+v", notification)
+	}
+
+	if _, err = f.uc.Retry(context.Background(), notification.ID); !errors.Is(err, domain.ErrNotificationNotDead) {
+		t.Errorf("Expected ErrNotificationNotDead, got %v", err)
+	}
+}
+
+func TestNotificationUsecase_UndeliverableIsDeadLetteredImmediately(t *testing.T) {
+	notifier := &fakeNotifier{channel: domain.NotificationChannelInApp, err: domain.ErrNotificationUndeliverable}
+	f := newNotificationFixture(notifier)
+
+	if err := f.uc.Enqueue(context.Background(), 1, domain.NotificationEventLineBound, "title", "body"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := f.uc.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if notification := f.repo.notifications[1]; notification.Status != domain.NotificationStatusDead {
+		t.Errorf("Expected dead letter, got %+v", notification)
+	}
+}
+
+func TestNotificationUsecase_EnqueueHonoursPreferences(t *testing.T) {
+	f := newNotificationFixture(
+		&fakeNotifier{channel: domain.NotificationChannelLine},
+		&fakeNotifier{channel: domain.NotificationChannelInApp},
+	)
+
+	_, err := f.uc.UpdatePreferences(context.Background(), 1, &domain.NotificationPreferences{
+		Channels: map[domain.NotificationEvent]map[domain.NotificationChannel]bool{
+			domain.NotificationEventLineBound: {
+				domain.NotificationChannelLine:  true,
+				domain.NotificationChannelInApp: false,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err = f.uc.Enqueue(context.Background(), 1, domain.NotificationEventLineBound, "title", "body"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(f.repo.notifications) != 1 || f.repo.notifications[1].Channel != domain.NotificationChannelLine {
+		t.Errorf("Expected a single Line notification, got %+v", f.repo.notifications)
+	}
+}
+
+func TestNotificationUsecase_QuietHoursDeferDelivery(t *testing.T) {
+	line := &fakeNotifier{channel: domain.NotificationChannelLine}
+	inApp := &fakeNotifier{channel: domain.NotificationChannelInApp}
+	f := newNotificationFixture(line, inApp)
+
+	// A window that starts this minute and ends a minute ago covers all but
+	// the previous minute of the day.
+	now := time.Now().UTC()
+	end := now.Add(-time.Minute)
+	_, err := f.uc.UpdatePreferences(context.Background(), 1, &domain.NotificationPreferences{
+		Channels: map[domain.NotificationEvent]map[domain.NotificationChannel]bool{
+			domain.NotificationEventLineBound: {domain.NotificationChannelLine: true},
+		},
+		QuietHours: &domain.QuietHours{Start: now.Format("15:04"), End: end.Format("15:04"), Timezone: "UTC"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err = f.uc.Enqueue(context.Background(), 1, domain.NotificationEventLineBound, "title", "body"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err = f.uc.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(line.sent) != 0 || len(inApp.sent) != 1 {
+		t.Fatalf("Expected only the in-app notification to be sent, got line=%d in_app=%d",
+			len(line.sent), len(inApp.sent))
+	}
+
+	deferred := f.repo.notifications[1]
+	if deferred.Status != domain.NotificationStatusPending || deferred.Attempts != 0 {
+		t.Errorf("Expected deferred notification to stay pending without an attempt, got %+v", deferred)
+	}
+	if !deferred.NextAttemptAt.After(now) {
+		t.Errorf("Expected delivery to be deferred past %v, got %v", now, deferred.NextAttemptAt)
+	}
+}
+
+func TestNotificationUsecase_UpdatePreferencesValidates(t *testing.T) {
+	f := newNotificationFixture()
+
+	_, err := f.uc.UpdatePreferences(context.Background(), 1, &domain.NotificationPreferences{
+		QuietHours: &domain.QuietHours{Start: "22:00", End: "07:00", Timezone: "Nowhere/Town"},
+	})
+	if !errors.Is(err, domain.ErrInvalidNotificationPreferences) {
+		t.Errorf("Expected ErrInvalidNotificationPreferences, got %v", err)
+	}
+
+	_, err = f.uc.UpdatePreferences(context.Background(), 42, &domain.NotificationPreferences{})
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got %v", err)
+	}
+}
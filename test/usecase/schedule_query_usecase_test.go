@@ -0,0 +1,85 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/infra"
+	"ministry-scheduler/internal/infra/memory"
+	"ministry-scheduler/internal/usecase"
+)
+
+func TestScheduleQueryUsecase_ResolvesInUserTimezone(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	users := memory.NewUserRepository(store)
+
+	// Sunday evening in UTC is already Monday morning in Taipei.
+	now := time.Date(2024, 10, 20, 23, 30, 0, 0, time.UTC)
+	schedule := usecase.NewScheduleQueryUsecase(users, time.UTC, func() time.Time { return now })
+
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	if err != nil {
+		t.Fatalf("Failed to load timezone: %v", err)
+	}
+	john, err := users.Create(ctx, &domain.User{Name: "John Doe", Email: "john@example.com", CreatedAt: now})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	preferences := domain.NotificationPreferences{
+		QuietHours: &domain.QuietHours{Start: "22:00", End: "07:00", Timezone: "Asia/Taipei"},
+	}
+	if err = users.UpdateNotificationPreferences(ctx, john.ID, preferences); err != nil {
+		t.Fatalf("Failed to save preferences: %v", err)
+	}
+	jane, err := users.Create(ctx, &domain.User{Name: "Jane Doe", Email: "jane@example.com", CreatedAt: now})
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		userID int64
+		today  time.Time
+	}{
+		{name: "user timezone", userID: john.ID, today: time.Date(2024, 10, 21, 0, 0, 0, 0, taipei)},
+		{name: "default timezone", userID: jane.ID, today: time.Date(2024, 10, 20, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, queryErr := schedule.Query(ctx, tt.userID, "今天有服事嗎？")
+			if queryErr != nil {
+				t.Fatalf("Expected no error, got %v", queryErr)
+			}
+			if !query.From.Equal(tt.today) || query.From.Location().String() != tt.today.Location().String() {
+				t.Errorf("Expected today to be %v, got %v", tt.today, query.From)
+			}
+		})
+	}
+
+	// The bot answers with the same day.
+	inApp := memory.NewInAppNotificationRepository(store)
+	notifications := usecase.NewNotificationUsecase(memory.NewNotificationRepository(store), inApp, users,
+		slog.New(slog.NewTextHandler(io.Discard, nil)), infra.NewInAppNotifier(inApp))
+	binding := usecase.NewLineBindingUsecase(
+		users, memory.NewLineBindingCodeRepository(store), memory.NewTransactor(store), notifications)
+	if err = users.BindLineAccount(ctx, john.ID, "U123"); err != nil {
+		t.Fatalf("Failed to bind: %v", err)
+	}
+
+	messages, err := usecase.NewLineBotUsecase(binding, schedule).HandleTextMessage(ctx, "U123", "今天有服事嗎？")
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("Expected one card, got %+v (%v)", messages, err)
+	}
+	bubble, ok := messages[0].Contents.(domain.FlexBubble)
+	if !ok || len(bubble.Body.Contents) < 2 {
+		t.Fatalf("Expected a card with the period, got %+v", messages[0].Contents)
+	}
+	if period := bubble.Body.Contents[1].Contents[1].Text; period != "10/21（一）" {
+		t.Errorf("Expected Monday 10/21, got %q", period)
+	}
+}
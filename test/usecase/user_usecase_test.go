@@ -7,75 +7,12 @@ import (
 	"time"
 
 	"ministry-scheduler/internal/domain"
+	"ministry-scheduler/internal/infra/memory"
 	"ministry-scheduler/internal/usecase"
 )
 
-type mockUserRepository struct {
-	users  map[int64]*domain.User
-	nextID int64
-}
-
-func newMockUserRepository() *mockUserRepository {
-	return &mockUserRepository{
-		users:  make(map[int64]*domain.User),
-		nextID: 1,
-	}
-}
-
-func (m *mockUserRepository) GetByID(_ context.Context, id int64) (*domain.User, error) {
-	user, exists := m.users[id]
-	if !exists {
-		return nil, domain.ErrUserNotFound
-	}
-	return user, nil
-}
-
-func (m *mockUserRepository) GetByEmail(_ context.Context, email string) (*domain.User, error) {
-	for _, user := range m.users {
-		if user.Email == email {
-			return user, nil
-		}
-	}
-	return nil, domain.ErrUserNotFound
-}
-
-func (m *mockUserRepository) Create(_ context.Context, user *domain.User) (*domain.User, error) {
-	user.ID = m.nextID
-	m.nextID++
-	m.users[user.ID] = user
-	return user, nil
-}
-
-func (m *mockUserRepository) Update(_ context.Context, user *domain.User) (*domain.User, error) {
-	if _, exists := m.users[user.ID]; !exists {
-		return nil, domain.ErrUserNotFound
-	}
-	m.users[user.ID] = user
-	return user, nil
-}
-
-func (m *mockUserRepository) Delete(_ context.Context, id int64) error {
-	if _, exists := m.users[id]; !exists {
-		return domain.ErrUserNotFound
-	}
-	delete(m.users, id)
-	return nil
-}
-
-func (m *mockUserRepository) List(_ context.Context, limit, offset int) ([]*domain.User, error) {
-	var users []*domain.User
-	count := 0
-	for _, user := range m.users {
-		if count >= offset && len(users) < limit {
-			users = append(users, user)
-		}
-		count++
-	}
-	return users, nil
-}
-
 func TestUserUsecase_CreateUser(t *testing.T) {
-	repo := newMockUserRepository()
+	repo := memory.NewUserRepository(memory.NewStore())
 	uc := usecase.NewUserUsecase(repo)
 
 	req := &domain.CreateUserRequest{
@@ -102,7 +39,7 @@ func TestUserUsecase_CreateUser(t *testing.T) {
 }
 
 func TestUserUsecase_CreateUserDuplicate(t *testing.T) {
-	repo := newMockUserRepository()
+	repo := memory.NewUserRepository(memory.NewStore())
 	uc := usecase.NewUserUsecase(repo)
 
 	req := &domain.CreateUserRequest{
@@ -122,17 +59,18 @@ func TestUserUsecase_CreateUserDuplicate(t *testing.T) {
 }
 
 func TestUserUsecase_GetUser(t *testing.T) {
-	repo := newMockUserRepository()
+	repo := memory.NewUserRepository(memory.NewStore())
 	uc := usecase.NewUserUsecase(repo)
 
 	originalUser := &domain.User{
-		ID:        1,
 		Name:      "John Doe",
 		Email:     "john@example.com",
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	repo.users[1] = originalUser
+	if _, err := repo.Create(context.Background(), originalUser); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
 
 	user, err := uc.GetUser(context.Background(), 1)
 	if err != nil {
@@ -149,7 +87,7 @@ func TestUserUsecase_GetUser(t *testing.T) {
 }
 
 func TestUserUsecase_GetUserNotFound(t *testing.T) {
-	repo := newMockUserRepository()
+	repo := memory.NewUserRepository(memory.NewStore())
 	uc := usecase.NewUserUsecase(repo)
 
 	_, err := uc.GetUser(context.Background(), 999)
@@ -159,17 +97,18 @@ func TestUserUsecase_GetUserNotFound(t *testing.T) {
 }
 
 func TestUserUsecase_UpdateUser(t *testing.T) {
-	repo := newMockUserRepository()
+	repo := memory.NewUserRepository(memory.NewStore())
 	uc := usecase.NewUserUsecase(repo)
 
 	originalUser := &domain.User{
-		ID:        1,
 		Name:      "John Doe",
 		Email:     "john@example.com",
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	repo.users[1] = originalUser
+	if _, err := repo.Create(context.Background(), originalUser); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
 
 	newName := "Jane Doe"
 	req := &domain.UpdateUserRequest{
@@ -191,17 +130,18 @@ func TestUserUsecase_UpdateUser(t *testing.T) {
 }
 
 func TestUserUsecase_DeleteUser(t *testing.T) {
-	repo := newMockUserRepository()
+	repo := memory.NewUserRepository(memory.NewStore())
 	uc := usecase.NewUserUsecase(repo)
 
 	user := &domain.User{
-		ID:        1,
 		Name:      "John Doe",
 		Email:     "john@example.com",
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
-	repo.users[1] = user
+	if _, err := repo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
 
 	err := uc.DeleteUser(context.Background(), 1)
 	if err != nil {
@@ -213,3 +153,52 @@ func TestUserUsecase_DeleteUser(t *testing.T) {
 		t.Errorf("Expected user to be deleted, but got %v", err)
 	}
 }
+
+func TestUserUsecase_ListUsersPaging(t *testing.T) {
+	repo := memory.NewUserRepository(memory.NewStore())
+	uc := usecase.NewUserUsecase(repo)
+
+	day := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+	// Bob and Carol share a creation time, so the ID breaks the tie.
+	for name, created := range map[string]time.Time{"Alice": day, "Bob": day.Add(time.Hour), "Carol": day.Add(time.Hour)} {
+		user := &domain.User{Name: name, Email: name + "@example.com", CreatedAt: created}
+		if _, err := repo.Create(context.Background(), user); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	first, err := uc.ListUsers(context.Background(), domain.UserFilter{}, "", domain.Page{Limit: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if first.Total != 3 || first.Count != 2 || first.NextCursor == "" {
+		t.Fatalf("Expected 2 of 3 users and a cursor, got %+v", first)
+	}
+
+	cursor, err := domain.DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatalf("Failed to decode cursor: %v", err)
+	}
+	page := domain.Page{Limit: 2, Offset: 5, After: cursor}
+	second, err := uc.ListUsers(context.Background(), domain.UserFilter{}, "", page)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if second.Count != 1 || second.NextCursor != "" {
+		t.Fatalf("Expected the last user without a cursor, got %+v", second)
+	}
+
+	seen := map[string]bool{}
+	for _, user := range append(first.Users, second.Users...) {
+		seen[user.Name] = true
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected every user exactly once, got %v", seen)
+	}
+
+	// A cursor only continues the ordering it was issued for.
+	_, err = uc.ListUsers(context.Background(), domain.UserFilter{}, domain.UserSortName, page)
+	if !errors.Is(err, domain.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}